@@ -0,0 +1,88 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Attachment metadata of an ir.attachment record
+type Attachment struct {
+	ID       int      `odoo:"id"`
+	Name     string   `odoo:"name"`
+	MimeType string   `odoo:"mimetype"`
+	FileSize int      `odoo:"file_size"`
+	ResModel string   `odoo:"res_model"`
+	ResID    int      `odoo:"res_id"`
+	Checksum string   `odoo:"checksum"`
+	Company  Many2One `odoo:"company_id"`
+}
+
+// UploadAttachment creates an ir.attachment named name holding the content
+// of r and linked to the record resID of model
+func (o *Odoo) UploadAttachment(model string, resID int, name string, r io.Reader) (id int, err error) {
+	return o.UploadAttachmentCtx(context.Background(), model, resID, name, r)
+}
+
+// UploadAttachmentCtx creates an ir.attachment named name holding the
+// content of r and linked to the record resID of model
+func (o *Odoo) UploadAttachmentCtx(ctx context.Context, model string, resID int, name string, r io.Reader) (id int, err error) {
+	var datas strings.Builder
+	enc := base64.NewEncoder(base64.StdEncoding, &datas)
+	if _, err := io.Copy(enc, r); err != nil {
+		return -1, fmt.Errorf("attachment read error: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return -1, fmt.Errorf("attachment encode error: %w", err)
+	}
+	id, _, err = o.CreateCtx(ctx, "ir.attachment", map[string]any{
+		"name":      name,
+		"res_model": model,
+		"res_id":    resID,
+		"datas":     datas.String(),
+	})
+	return id, err
+}
+
+// DownloadAttachment returns the content and metadata of the ir.attachment
+// id
+func (o *Odoo) DownloadAttachment(id int) (io.ReadCloser, Attachment, error) {
+	return o.DownloadAttachmentCtx(context.Background(), id)
+}
+
+// DownloadAttachmentCtx returns the content and metadata of the
+// ir.attachment id
+func (o *Odoo) DownloadAttachmentCtx(ctx context.Context, id int) (io.ReadCloser, Attachment, error) {
+	var meta Attachment
+	recs, err := o.ReadCtx(ctx, "ir.attachment", []int{id}, append(StructFields(meta), "datas"))
+	if err != nil {
+		return nil, meta, err
+	}
+	if len(recs) == 0 {
+		return nil, meta, fmt.Errorf("attachment %d not found", id)
+	}
+	if err := DecodeIn(recs[0], &meta, o.Location()); err != nil {
+		return nil, meta, err
+	}
+	datas, _ := recs[0]["datas"].(string)
+	return io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(datas))), meta, nil
+}
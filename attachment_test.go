@@ -0,0 +1,91 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestAttachment(t *testing.T) {
+	content := []byte("%PDF-1.4 test")
+	var created map[string]any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		args := params["args"].([]any)
+		switch args[4] {
+		case "create":
+			created = args[5].([]any)[0].(map[string]any)
+			return 12.0
+		case "read":
+			return []any{map[string]any{
+				"id": 12.0, "name": "invoice.pdf", "mimetype": "application/pdf", "file_size": float64(len(content)),
+				"res_model": "account.move", "res_id": 4.0, "checksum": "abc", "company_id": false, "datas": created["datas"],
+			}}
+		}
+		return false
+	})
+	defer done()
+
+	id, err := o.UploadAttachment("account.move", 4, "invoice.pdf", bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 12 || created["datas"] != base64.StdEncoding.EncodeToString(content) || created["res_model"] != "account.move" {
+		t.Errorf("unexpected attachment %d %v", id, created)
+	}
+
+	r, meta, err := o.DownloadAttachment(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("expected %q, got %q", content, got)
+	}
+	expected := Attachment{ID: 12, Name: "invoice.pdf", MimeType: "application/pdf", FileSize: len(content), ResModel: "account.move", ResID: 4, Checksum: "abc"}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("expected %+v, got %+v", expected, meta)
+	}
+}
+
+func TestBinaryField(t *testing.T) {
+	type product struct {
+		Image []byte `odoo:"image_1920"`
+	}
+	record, err := Encode(product{Image: []byte{0xff, 0xd8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record["image_1920"] != "/9g=" {
+		t.Errorf("expected base64 image, got %v", record["image_1920"])
+	}
+	var got product
+	if err := Decode(record, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Image, []byte{0xff, 0xd8}) {
+		t.Errorf("unexpected image %v", got.Image)
+	}
+}
@@ -0,0 +1,195 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout applied to the pooled client when Timeout is not set,
+// a negative Timeout disables the client timeout
+const DefaultTimeout = 5 * time.Second
+
+// connMu guards the lazy creation of the connection state
+var connMu sync.Mutex
+
+// conn holds the state shared by every call made through an Odoo value
+type conn struct {
+	mu     sync.Mutex // guards client and owned
+	client *http.Client
+	owned  bool // client and transport built by the library
+	jar    http.CookieJar
+	schema schema
+	// authMu serializes the logins of the copies sharing the connection
+	authMu  sync.Mutex
+	limiter *limiter
+}
+
+// conn returns the connection state, creating it on first use
+func (o *Odoo) conn() *conn {
+	connMu.Lock()
+	defer connMu.Unlock()
+	if o.st == nil {
+		o.st = o.newConn()
+	}
+	return o.st
+}
+
+// resetConn rebuilds the connection state from the current options
+func (o *Odoo) resetConn() {
+	connMu.Lock()
+	defer connMu.Unlock()
+	o.st = o.newConn()
+}
+
+// reconfigureClient rebuilds the client of a connection in use after one of
+// its options changed, the copies made by Derive get the new client too
+func (o *Odoo) reconfigureClient() {
+	connMu.Lock()
+	st := o.st
+	connMu.Unlock()
+	if st == nil {
+		return
+	}
+	client, owned := o.newHTTPClient(), o.ownsClient()
+	st.mu.Lock()
+	old, oldOwned := st.client, st.owned
+	st.client, st.owned = client, owned
+	st.mu.Unlock()
+	if oldOwned {
+		old.CloseIdleConnections()
+	}
+}
+
+// reconfigureLimiter applies the rate and in-flight limits to a connection
+// in use
+func (o *Odoo) reconfigureLimiter() {
+	connMu.Lock()
+	st := o.st
+	connMu.Unlock()
+	if st != nil {
+		st.limiter.configure(o.RateLimit, o.RateBurst, o.MaxInFlight)
+	}
+}
+
+// httpClient returns the current client of the connection
+func (st *conn) httpClient() *http.Client {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.client
+}
+
+func (o *Odoo) newConn() *conn {
+	jar, _ := cookiejar.New(nil)
+	return &conn{
+		client:  o.newHTTPClient(),
+		owned:   o.ownsClient(),
+		jar:     jar,
+		limiter: newLimiter(o.RateLimit, o.RateBurst, o.MaxInFlight),
+	}
+}
+
+// ownsClient reports whether newHTTPClient builds its own transport
+func (o *Odoo) ownsClient() bool {
+	return o.HTTPClient == nil && o.Transport == nil
+}
+
+// newHTTPClient returns HTTPClient or a client wrapping Transport or a
+// pooled default transport
+func (o *Odoo) newHTTPClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	transport := o.Transport
+	if transport == nil {
+		transport = o.newTransport()
+	}
+	timeout := o.Timeout
+	switch {
+	case timeout == 0:
+		timeout = DefaultTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// newTransport clones the default transport so connections are pooled per
+// Odoo value
+func (o *Odoo) newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 32
+	if o.Proxy != nil {
+		t.Proxy = http.ProxyURL(o.Proxy)
+	}
+	t.TLSClientConfig = o.tlsConfig()
+	return t
+}
+
+// tlsConfig merges the TLS options over TLSConfig
+func (o *Odoo) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if o.TLSConfig != nil {
+		cfg = o.TLSConfig.Clone()
+	}
+	if o.RootCAs != nil {
+		cfg.RootCAs = o.RootCAs
+	}
+	if len(o.Certificates) > 0 {
+		cfg.Certificates = append(cfg.Certificates, o.Certificates...)
+	}
+	if o.MinTLSVersion != 0 {
+		cfg.MinVersion = o.MinTLSVersion
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if o.InsecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg
+}
+
+// LoadCertPool returns the system cert pool extended with the PEM encoded
+// certificates in files
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file error: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,198 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransport(t *testing.T) {
+	calls := 0
+	o := NewOdoo().
+		WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if got := r.Header.Get("X-Odoo-Test"); got != "yes" {
+				t.Errorf("expected header X-Odoo-Test: yes, got %q", got)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"jsonrpc":"2.0","id":1,"result":true}`)),
+			}, nil
+		})).
+		WithHeader("X-Odoo-Test", "yes").
+		WithTimeout(time.Minute)
+	o.URL = "http://odoo.test/jsonrpc"
+
+	client := o.conn().client
+	for i := 0; i < 3; i++ {
+		if _, err := o.Call("common", "version"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	if o.conn().client != client {
+		t.Errorf("expected http client to be reused")
+	}
+	if client.Timeout != time.Minute {
+		t.Errorf("expected timeout %v, got %v", time.Minute, client.Timeout)
+	}
+}
+
+func TestDefaultTransport(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.test:3128")
+	o := NewOdoo().WithProxy(proxy)
+	client := o.newHTTPClient()
+	if client.Timeout != DefaultTimeout {
+		t.Errorf("expected timeout %v, got %v", DefaultTimeout, client.Timeout)
+	}
+	tr, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.Transport)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://odoo.test/jsonrpc", nil)
+	got, err := tr.Proxy(req)
+	if err != nil || got.String() != proxy.String() {
+		t.Errorf("expected proxy %v, got %v (%v)", proxy, got, err)
+	}
+
+	custom := &http.Client{}
+	if NewOdoo().WithHTTPClient(custom).newHTTPClient() != custom {
+		t.Errorf("expected caller supplied http client")
+	}
+}
+
+func TestTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":true}`)
+	}))
+	defer srv.Close()
+
+	call := func(o *Odoo) error {
+		o.URL = srv.URL + "/jsonrpc"
+		_, err := o.Call("common", "version")
+		return err
+	}
+
+	if err := call(NewOdoo().WithSchema("https").WithHostname("localhost")); err == nil {
+		t.Errorf("expected certificate verification error")
+	}
+	if err := call(NewOdoo().WithInsecureSkipVerify(true)); err != nil {
+		t.Errorf("expected insecure call to succeed, got %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := LoadCertPool(caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := call(NewOdoo().WithRootCAs(pool)); err != nil {
+		t.Errorf("expected private ca call to succeed, got %v", err)
+	}
+
+	if _, err := LoadCertPool(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Errorf("expected error loading missing ca file")
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	pool := x509.NewCertPool()
+	cert := tls.Certificate{Certificate: [][]byte{{1}}}
+	cfg := NewOdoo().
+		WithTLSConfig(&tls.Config{ServerName: "odoo.internal"}).
+		WithRootCAs(pool).
+		WithClientCertificate(cert).
+		WithMinTLSVersion(tls.VersionTLS13).
+		tlsConfig()
+	if cfg.ServerName != "odoo.internal" || cfg.RootCAs != pool || len(cfg.Certificates) != 1 ||
+		cfg.MinVersion != tls.VersionTLS13 || cfg.InsecureSkipVerify {
+		t.Errorf("unexpected tls config %+v", cfg)
+	}
+	if cfg := NewOdoo().tlsConfig(); cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected default min version tls 1.2, got %x", cfg.MinVersion)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	o, done := newRPCServer(t, func(params map[string]any) any { return 1.0 })
+	defer done()
+	if _, err := o.Count("res.partner", nil); err != nil {
+		t.Fatal(err)
+	}
+	st := o.conn()
+	client := st.httpClient()
+	u, _ := url.Parse(o.URL)
+	st.jar.SetCookies(u, []*http.Cookie{{Name: "session_id", Value: "abc"}})
+	st.schema.set("res.partner", "", map[string]FieldInfo{"name": {Type: "char"}})
+	copied := o.Derive(nil)
+
+	o.WithTimeout(time.Minute).WithRateLimit(20, 1)
+	if o.conn() != st || copied.conn() != st {
+		t.Fatalf("expected the connection to stay shared")
+	}
+	if c := st.httpClient(); c == client || c.Timeout != time.Minute {
+		t.Errorf("expected the client to be rebuilt with timeout %v", time.Minute)
+	}
+	if cookies := st.jar.Cookies(u); len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Errorf("expected session cookies to be kept, got %v", cookies)
+	}
+	if _, ok := st.schema.get("res.partner", ""); !ok {
+		t.Errorf("expected the fields cache to be kept")
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := copied.Count("res.partner", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected the rate limit set after first use to apply to copies, took %v", elapsed)
+	}
+
+	custom := &http.Client{}
+	if o.WithHTTPClient(custom); st.httpClient() != custom || st.owned {
+		t.Errorf("expected the caller supplied client")
+	}
+	if o.WithTimeout(time.Second); st.httpClient() != custom {
+		t.Errorf("expected the caller supplied client to be kept")
+	}
+}
@@ -0,0 +1,212 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ppreeper/odoojrpc"
+)
+
+// Schema maps model names to their fields
+type Schema map[string]map[string]odoojrpc.FieldInfo
+
+// ReadDump reads a JSON dump of fields_get results keyed by model name,
+// {"res.partner": {"name": {"type": "char", ...}, ...}, ...}
+func ReadDump(r io.Reader) (Schema, error) {
+	var dump map[string]map[string]map[string]any
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("dump decode error: %w", err)
+	}
+	schema := Schema{}
+	for model, fields := range dump {
+		schema[model] = map[string]odoojrpc.FieldInfo{}
+		for name, attrs := range fields {
+			var field odoojrpc.FieldInfo
+			if err := odoojrpc.Decode(attrs, &field); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", model, name, err)
+			}
+			field.Name = name
+			schema[model][name] = field
+		}
+	}
+	return schema, nil
+}
+
+// WriteDump writes schema in the format read by ReadDump
+func WriteDump(w io.Writer, schema Schema) error {
+	dump := map[string]map[string]map[string]any{}
+	for model, fields := range schema {
+		dump[model] = map[string]map[string]any{}
+		for name, field := range fields {
+			attrs, err := odoojrpc.Encode(field)
+			if err != nil {
+				return err
+			}
+			delete(attrs, "name")
+			if len(field.Selection) > 0 {
+				selection := []any{}
+				for _, option := range field.Selection {
+					selection = append(selection, []any{option.Value, option.Label})
+				}
+				attrs["selection"] = selection
+			} else {
+				delete(attrs, "selection")
+			}
+			dump[model][name] = attrs
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
+// goTypes of the Odoo field types, other types are decoded as any
+var goTypes = map[string]string{
+	"boolean":            "bool",
+	"char":               "string",
+	"text":               "string",
+	"html":               "string",
+	"integer":            "int",
+	"float":              "float64",
+	"monetary":           "float64",
+	"selection":          "odoojrpc.Selection",
+	"date":               "odoojrpc.Date",
+	"datetime":           "odoojrpc.DateTime",
+	"binary":             "[]byte",
+	"image":              "[]byte",
+	"many2one":           "odoojrpc.Many2One",
+	"many2one_reference": "int",
+	"one2many":           "odoojrpc.IDs",
+	"many2many":          "odoojrpc.IDs",
+}
+
+// initialisms kept upper case in Go names
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "uid": "UID", "url": "URL", "uri": "URI", "api": "API",
+	"html": "HTML", "http": "HTTP", "json": "JSON", "xml": "XML", "uuid": "UUID",
+	"vat": "VAT", "ip": "IP", "sku": "SKU", "pdf": "PDF", "csv": "CSV",
+}
+
+// goName converts an Odoo model or field name to an exported Go name
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if up, ok := initialisms[strings.ToLower(part)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// Generate writes the Go source of the structs of models in package pkg
+func Generate(w io.Writer, pkg string, schema Schema, models []string) error {
+	if len(models) == 0 {
+		for model := range schema {
+			models = append(models, model)
+		}
+	}
+	sort.Strings(models)
+
+	var body bytes.Buffer
+	for _, model := range models {
+		fields, ok := schema[model]
+		if !ok {
+			return fmt.Errorf("model %s not found", model)
+		}
+		generateModel(&body, model, fields)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by odoogen. DO NOT EDIT.\n\npackage %s\n", pkg)
+	if bytes.Contains(body.Bytes(), []byte("odoojrpc.")) {
+		fmt.Fprintf(&b, "\nimport \"github.com/ppreeper/odoojrpc\"\n")
+	}
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("format error: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// generateModel writes the struct and field name constants of model
+func generateModel(b *bytes.Buffer, model string, fields map[string]odoojrpc.FieldInfo) {
+	typeName := goName(model)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "id" || names[j] == "id" {
+			return names[i] == "id"
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintf(b, "\n// %s model name\nconst %sModel = %q\n", typeName, typeName, model)
+	fmt.Fprintf(b, "\n// %s field names\nconst (\n", typeName)
+	for _, name := range names {
+		fmt.Fprintf(b, "%sField%s = %q\n", typeName, goName(name), name)
+	}
+	fmt.Fprintf(b, ")\n")
+
+	fmt.Fprintf(b, "\n// %s record of %s, odoojrpc.Encode leaves its zero valued fields out\ntype %s struct {\n", typeName, model, typeName)
+	for _, name := range names {
+		field := fields[name]
+		goType, ok := goTypes[field.Type]
+		if !ok {
+			goType = "any"
+		}
+		comment := field.String
+		if field.Relation != "" {
+			comment = strings.TrimSpace(comment + " (" + field.Relation + ")")
+		}
+		if comment != "" {
+			comment = " // " + strings.ReplaceAll(comment, "\n", " ")
+		}
+		tag := name
+		if name != "id" {
+			// so Encode of a partly filled record does not clear the
+			// other fields, readonly ones included
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "%s %s `odoo:%q`%s\n", goName(name), goType, tag, comment)
+	}
+	fmt.Fprintf(b, "}\n")
+}
@@ -0,0 +1,109 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDump = `{
+  "sale.order": {
+    "id": {"type": "integer", "string": "ID"},
+    "name": {"type": "char", "string": "Order Reference", "required": true},
+    "partner_id": {"type": "many2one", "string": "Customer", "relation": "res.partner"},
+    "order_line": {"type": "one2many", "string": "Order Lines", "relation": "sale.order.line"},
+    "state": {"type": "selection", "string": "Status", "selection": [["draft", "Quotation"], ["sale", "Sales Order"]]},
+    "date_order": {"type": "datetime", "string": "Order Date"},
+    "amount_total": {"type": "monetary", "string": "Total"},
+    "2fa_code": {"type": "properties", "string": false}
+  }
+}`
+
+func TestGenerate(t *testing.T) {
+	schema, err := ReadDump(strings.NewReader(testDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := Generate(&b, "models", schema, nil); err != nil {
+		t.Fatal(err)
+	}
+	src := b.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	flat := strings.Join(strings.Fields(src), " ")
+	for _, line := range []string{
+		`const SaleOrderModel = "sale.order"`,
+		`SaleOrderFieldPartnerID = "partner_id"`,
+		"ID int `odoo:\"id\"`",
+		"PartnerID odoojrpc.Many2One `odoo:\"partner_id,omitempty\"` // Customer (res.partner)",
+		"OrderLine odoojrpc.IDs `odoo:\"order_line,omitempty\"`",
+		"State odoojrpc.Selection `odoo:\"state,omitempty\"`",
+		"DateOrder odoojrpc.DateTime `odoo:\"date_order,omitempty\"`",
+		"AmountTotal float64 `odoo:\"amount_total,omitempty\"`",
+		"X2faCode any `odoo:\"2fa_code,omitempty\"`",
+	} {
+		if !strings.Contains(flat, line) {
+			t.Errorf("expected %q in\n%s", line, src)
+		}
+	}
+	if strings.Index(flat, "ID int") > strings.Index(flat, "AmountTotal float64") {
+		t.Errorf("expected id as the first field\n%s", src)
+	}
+}
+
+func TestDump(t *testing.T) {
+	schema, err := ReadDump(strings.NewReader(testDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := WriteDump(&b, schema); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadDump(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, schema) {
+		t.Errorf("expected %+v, got %+v", schema, got)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	patterns := map[string]string{
+		"res.partner":       "ResPartner",
+		"partner_id":        "PartnerID",
+		"tag_ids":           "TagIDs",
+		"website_url":       "WebsiteURL",
+		"x_studio_field_1a": "XStudioField1a",
+		"3d_model":          "X3dModel",
+	}
+	for name, expected := range patterns {
+		if got := goName(name); got != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, got)
+		}
+	}
+}
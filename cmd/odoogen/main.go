@@ -0,0 +1,121 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+
+// Command odoogen generates Go structs tagged for odoojrpc from the
+// fields_get schema of a live server or of a JSON dump.
+//
+//	odoogen -host odoo.example.com -db prod -user admin -models res.partner,sale.order -o models.go
+//	odoogen -host odoo.example.com -db prod -user admin -models res.partner -dump schema.json
+//	odoogen -in schema.json -package models -o models.go
+//
+// The password is read from the ODOO_PASSWORD environment variable.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ppreeper/odoojrpc"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "odoogen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		schemaFlag = flag.String("schema", "https", "server schema, http or https")
+		host       = flag.String("host", "localhost", "server hostname")
+		port       = flag.Int("port", 443, "server port")
+		database   = flag.String("db", "odoo", "database")
+		username   = flag.String("user", "admin", "username")
+		models     = flag.String("models", "", "comma separated list of models")
+		in         = flag.String("in", "", "read the schema from a JSON dump instead of a server")
+		dump       = flag.String("dump", "", "write the schema to a JSON dump instead of generating code")
+		out        = flag.String("o", "", "output file, default stdout")
+		pkg        = flag.String("package", "models", "package name of the generated code")
+	)
+	flag.Parse()
+
+	var names []string
+	if *models != "" {
+		for _, model := range strings.Split(*models, ",") {
+			names = append(names, strings.TrimSpace(model))
+		}
+	}
+
+	var schema Schema
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if schema, err = ReadDump(f); err != nil {
+			return err
+		}
+	} else {
+		if len(names) == 0 {
+			return fmt.Errorf("-models is required when reading from a server")
+		}
+		o := odoojrpc.NewOdoo().
+			WithSchema(*schemaFlag).
+			WithHostname(*host).
+			WithPort(*port).
+			WithDatabase(*database).
+			WithUsername(*username).
+			WithPassword(os.Getenv("ODOO_PASSWORD"))
+		if err := o.Login(); err != nil {
+			return err
+		}
+		schema = Schema{}
+		for _, model := range names {
+			fields, err := o.Fields(model)
+			if err != nil {
+				return err
+			}
+			schema[model] = fields
+		}
+	}
+
+	if *dump != "" {
+		f, err := os.Create(*dump)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return WriteDump(f, schema)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return Generate(w, *pkg, schema, names)
+}
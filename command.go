@@ -0,0 +1,116 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Command operations on one2many and many2many fields
+const (
+	CommandOpCreate = 0
+	CommandOpUpdate = 1
+	CommandOpDelete = 2
+	CommandOpUnlink = 3
+	CommandOpLink   = 4
+	CommandOpClear  = 5
+	CommandOpSet    = 6
+)
+
+// Command is an x2many write command, it encodes to the (op, id, value)
+// tuple expected by create and write
+type Command struct {
+	Op     int
+	ID     int
+	Values any // map[string]any or a struct tagged for Encode
+	IDs    []int
+}
+
+// CommandCreate creates a new record from values and links it
+func CommandCreate(values any) Command {
+	return Command{Op: CommandOpCreate, Values: values}
+}
+
+// CommandUpdate writes values on the linked record id
+func CommandUpdate(id int, values any) Command {
+	return Command{Op: CommandOpUpdate, ID: id, Values: values}
+}
+
+// CommandDelete removes the record id from the relation and deletes it
+func CommandDelete(id int) Command {
+	return Command{Op: CommandOpDelete, ID: id}
+}
+
+// CommandUnlink removes the record id from the relation
+func CommandUnlink(id int) Command {
+	return Command{Op: CommandOpUnlink, ID: id}
+}
+
+// CommandLink adds the existing record id to the relation
+func CommandLink(id int) Command {
+	return Command{Op: CommandOpLink, ID: id}
+}
+
+// CommandClear removes every record from the relation
+func CommandClear() Command {
+	return Command{Op: CommandOpClear}
+}
+
+// CommandSet replaces the records of the relation by ids
+func CommandSet(ids ...int) Command {
+	return Command{Op: CommandOpSet, IDs: ids}
+}
+
+// MarshalJSON encodes the command tuple
+func (c Command) MarshalJSON() ([]byte, error) {
+	switch c.Op {
+	case CommandOpCreate, CommandOpUpdate:
+		values, err := commandValues(c.Values)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal([]any{c.Op, c.ID, values})
+	case CommandOpSet:
+		ids := c.IDs
+		if ids == nil {
+			ids = []int{}
+		}
+		return json.Marshal([]any{c.Op, 0, ids})
+	default:
+		return json.Marshal([]any{c.Op, c.ID, 0})
+	}
+}
+
+// commandValues returns values as a map, structs are encoded
+func commandValues(values any) (any, error) {
+	if values == nil {
+		return map[string]any{}, nil
+	}
+	rv := reflect.ValueOf(values)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		return Encode(values)
+	}
+	return normalize(values), nil
+}
+
+// Commands is the value of an x2many field in a create or write
+type Commands []Command
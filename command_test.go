@@ -0,0 +1,81 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testOrderLine struct {
+	ProductID Many2One `odoo:"product_id"`
+	Quantity  float64  `odoo:"product_uom_qty"`
+}
+
+var commandPatterns = []struct {
+	command  Command
+	expected string
+}{
+	{CommandCreate(map[string]any{"name": "Line"}), `[0,0,{"name":"Line"}]`},
+	{CommandCreate(testOrderLine{ProductID: NewMany2One(5), Quantity: 2}), `[0,0,{"product_id":5,"product_uom_qty":2}]`},
+	{CommandCreate(nil), `[0,0,{}]`},
+	{CommandUpdate(7, map[string]any{"name": "Line"}), `[1,7,{"name":"Line"}]`},
+	{CommandDelete(7), `[2,7,0]`},
+	{CommandUnlink(7), `[3,7,0]`},
+	{CommandLink(7), `[4,7,0]`},
+	{CommandClear(), `[5,0,0]`},
+	{CommandSet(), `[6,0,[]]`},
+	{CommandSet(7, 8), `[6,0,[7,8]]`},
+}
+
+func TestCommand(t *testing.T) {
+	for i, pattern := range commandPatterns {
+		b, err := json.Marshal(pattern.command)
+		if err != nil {
+			t.Fatalf("\n[%d]: %v", i, err)
+		}
+		if string(b) != pattern.expected {
+			t.Errorf("\n[%d]: expected %s, got %s", i, pattern.expected, b)
+		}
+	}
+}
+
+func TestCreateWithCommands(t *testing.T) {
+	var got string
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		b, _ := json.Marshal(params["args"].([]any)[5].([]any)[0])
+		got = string(b)
+		return 10.0
+	})
+	defer done()
+
+	row, _, err := o.Create("sale.order", map[string]any{
+		"partner_id": NewMany2One(3),
+		"order_line": Commands{
+			CommandCreate(testOrderLine{ProductID: NewMany2One(5), Quantity: 2}),
+			CommandLink(9),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"order_line":[[0,0,{"product_id":5,"product_uom_qty":2}],[4,9,0]],"partner_id":3}`
+	if row != 10 || got != expected {
+		t.Errorf("expected row 10 with %s, got %d with %s", expected, row, got)
+	}
+}
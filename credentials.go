@@ -0,0 +1,168 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrAPIKeySession error on an API key used for session authentication,
+// Odoo only accepts API keys on the RPC endpoints
+var ErrAPIKeySession = errors.New("api keys cannot authenticate a web session")
+
+// redacted replaces the secrets in error messages and formatted values
+const redacted = "[REDACTED]"
+
+// Credentials of an Odoo user, Secret is the password or, with APIKey, an
+// Odoo 14+ user API key
+type Credentials struct {
+	Username string
+	Secret   string
+	APIKey   bool
+}
+
+func (c Credentials) String() string {
+	return fmt.Sprintf("{Username:%s Secret:%s APIKey:%t}", c.Username, redacted, c.APIKey)
+}
+
+func (c Credentials) GoString() string {
+	return "odoojrpc.Credentials" + c.String()
+}
+
+// CredentialProvider supplies the credentials used by Login and every model
+// call, so rotated secrets are picked up without a new Odoo value
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials provides fixed credentials
+type StaticCredentials Credentials
+
+func (c StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(c), nil
+}
+
+func (c StaticCredentials) String() string {
+	return Credentials(c).String()
+}
+
+func (c StaticCredentials) GoString() string {
+	return "odoojrpc.StaticCredentials" + Credentials(c).String()
+}
+
+// CredentialFunc adapts a function, e.g. a secret manager lookup, to a
+// CredentialProvider
+type CredentialFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// EnvCredentials reads the username and the secret from the environment
+// variables userVar and secretVar
+func EnvCredentials(userVar, secretVar string, apiKey bool) CredentialProvider {
+	return CredentialFunc(func(ctx context.Context) (Credentials, error) {
+		secret, ok := os.LookupEnv(secretVar)
+		if !ok {
+			return Credentials{}, fmt.Errorf("credentials error: %s is not set", secretVar)
+		}
+		return Credentials{Username: os.Getenv(userVar), Secret: secret, APIKey: apiKey}, nil
+	})
+}
+
+// FileCredentials reads the secret of username from path on every use, the
+// trailing newline is removed
+func FileCredentials(username, path string, apiKey bool) CredentialProvider {
+	return CredentialFunc(func(ctx context.Context) (Credentials, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("credentials error: %w", err)
+		}
+		return Credentials{Username: username, Secret: strings.TrimRight(string(b), "\r\n"), APIKey: apiKey}, nil
+	})
+}
+
+// credentials returns the credentials of the provider, or Username and
+// Password
+func (o *Odoo) credentials(ctx context.Context) (Credentials, error) {
+	if o.CredentialProvider == nil {
+		return Credentials{Username: o.Username, Secret: o.Password}, nil
+	}
+	c, err := o.CredentialProvider.Credentials(ctx)
+	if err != nil {
+		return c, err
+	}
+	if c.Username == "" {
+		c.Username = o.Username
+	}
+	return c, nil
+}
+
+// String describes o without its password
+func (o Odoo) String() string {
+	return fmt.Sprintf("{URL:%s Database:%s Username:%s UID:%d}", o.URL, o.Database, o.Username, o.UID)
+}
+
+func (o Odoo) GoString() string {
+	return "odoojrpc.Odoo" + o.String()
+}
+
+// redactError removes secret from the messages of err, the OdooError and
+// TransportError in its chain are scrubbed in place
+func redactError(err error, secret string) error {
+	if err == nil || secret == "" {
+		return err
+	}
+	var oe *OdooError
+	if errors.As(err, &oe) {
+		oe.Message = strings.ReplaceAll(oe.Message, secret, redacted)
+		oe.DataMessage = strings.ReplaceAll(oe.DataMessage, secret, redacted)
+		oe.Debug = strings.ReplaceAll(oe.Debug, secret, redacted)
+		for i, arg := range oe.Arguments {
+			if s, ok := arg.(string); ok {
+				oe.Arguments[i] = strings.ReplaceAll(s, secret, redacted)
+			}
+		}
+	}
+	var te *TransportError
+	if errors.As(err, &te) {
+		te.Body = strings.ReplaceAll(te.Body, secret, redacted)
+	}
+	if !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return &redactedError{err: err, secret: secret}
+}
+
+// redactedError hides a secret from the message of the wrapped error
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, redacted)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
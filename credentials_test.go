@@ -0,0 +1,129 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialProvider(t *testing.T) {
+	var secrets []any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		args := params["args"].([]any)
+		if params["method"] == "login" {
+			secrets = append(secrets, args[2])
+			return 2.0
+		}
+		secrets = append(secrets, args[2])
+		return 1.0
+	})
+	defer done()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key")
+	if err := os.WriteFile(path, []byte("key-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o.WithCredentials(FileCredentials("admin", path, true))
+	if err := o.Login(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("key-2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Count("res.partner", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_ODOO_USER", "admin")
+	t.Setenv("TEST_ODOO_KEY", "key-3")
+	o.WithCredentials(EnvCredentials("TEST_ODOO_USER", "TEST_ODOO_KEY", true))
+	if _, err := o.Count("res.partner", nil); err != nil {
+		t.Fatal(err)
+	}
+	o.WithCredentials(EnvCredentials("TEST_ODOO_USER", "TEST_ODOO_MISSING", true))
+	if _, err := o.Count("res.partner", nil); err == nil {
+		t.Errorf("expected an error for a missing environment variable")
+	}
+
+	o.WithAPIKey("admin", "key-4")
+	if _, err := o.Count("res.partner", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(secrets); got != "[key-1 key-2 key-3 key-4]" {
+		t.Errorf("unexpected secrets %s", got)
+	}
+
+	o.WithAuthMode(AuthSession)
+	if err := o.Login(); !errors.Is(err, ErrAPIKeySession) {
+		t.Errorf("expected ErrAPIKeySession, got %v", err)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{
+			"message": "Odoo Server Error",
+			"data": map[string]any{
+				"name":      "odoo.exceptions.AccessDenied",
+				"message":   "bad key s3cr3t",
+				"debug":     "Traceback: execute_kw('odoo', 2, 's3cr3t')",
+				"arguments": []any{"s3cr3t"},
+			},
+		}})
+	}))
+	defer srv.Close()
+
+	var c CredentialProvider = CredentialFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{Username: "admin", Secret: "s3cr3t"}, nil
+	})
+	o := NewOdoo().WithCredentials(c)
+	o.URL = srv.URL + "/jsonrpc"
+
+	for _, err := range []error{o.Login(), func() error { _, err := o.Count("res.partner", nil); return err }()} {
+		var oe *OdooError
+		if !errors.As(err, &oe) || !IsAccessDenied(err) {
+			t.Fatalf("expected *OdooError, got %v", err)
+		}
+		if strings.Contains(err.Error(), "s3cr3t") || strings.Contains(oe.Debug, "s3cr3t") || oe.Arguments[0] != redacted {
+			t.Errorf("secret not redacted: %v %+v", err, oe)
+		}
+	}
+
+	static := StaticCredentials{Username: "admin", Secret: "s3cr3t"}
+	o.WithPassword("s3cr3t")
+	printed := []string{fmt.Sprint(static), fmt.Sprintf("%+v", static), fmt.Sprintf("%#v", Credentials(static))}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		printed = append(printed, fmt.Sprintf(format, o), fmt.Sprintf(format, *o))
+	}
+	for _, s := range printed {
+		if strings.Contains(s, "s3cr3t") {
+			t.Errorf("secret not redacted: %s", s)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+)
+
+// DefaultBatchSize of the pages fetched by a Cursor
+const DefaultBatchSize = 500
+
+// Cursor streams the records of a search_read page by page
+//
+//	c := o.SearchReadCursor("stock.move", filter, fields, 1000, SearchOptions{})
+//	for c.Next() {
+//		rec := c.Record()
+//	}
+//	if err := c.Err(); err != nil {
+//		// handle err
+//	}
+type Cursor struct {
+	o      *Odoo
+	ctx    context.Context
+	model  string
+	filter []any
+	fields []string
+	opts   SearchOptions
+	batch  int
+
+	page   []map[string]any
+	rec    map[string]any
+	read   int
+	lastID int
+	done   bool
+	err    error
+}
+
+// SearchReadCursor returns a Cursor over the records matching filter
+func (o *Odoo) SearchReadCursor(model string, filter []any, fields []string, batchSize int, opts SearchOptions) *Cursor {
+	return o.SearchReadCursorCtx(context.Background(), model, filter, fields, batchSize, opts)
+}
+
+// SearchReadCursorCtx returns a Cursor over the records matching filter,
+// pages are fetched with ctx. Without an Order the records are paged by id,
+// otherwise by offset. opts.Limit caps the total number of records.
+func (o *Odoo) SearchReadCursorCtx(ctx context.Context, model string, filter []any, fields []string, batchSize int, opts SearchOptions) *Cursor {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Cursor{
+		o:      o,
+		ctx:    ctx,
+		model:  model,
+		filter: filter,
+		fields: fields,
+		opts:   opts,
+		batch:  batchSize,
+	}
+}
+
+// Next advances to the next record, fetching a new page when needed
+func (c *Cursor) Next() bool {
+	c.rec = nil
+	if c.err != nil || (c.opts.Limit > 0 && c.read >= c.opts.Limit) {
+		return false
+	}
+	if len(c.page) == 0 {
+		if c.done {
+			return false
+		}
+		if c.err = c.fetch(); c.err != nil || len(c.page) == 0 {
+			return false
+		}
+	}
+	c.rec, c.page = c.page[0], c.page[1:]
+	c.read++
+	return true
+}
+
+// Record returns the current record
+func (c *Cursor) Record() map[string]any {
+	return c.rec
+}
+
+// Err returns the error that stopped the cursor
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// fetch reads the next page
+func (c *Cursor) fetch() error {
+	limit := c.batch
+	if c.opts.Limit > 0 && c.opts.Limit-c.read < limit {
+		limit = c.opts.Limit - c.read
+	}
+	opts := SearchOptions{Limit: limit, Context: c.opts.Context}
+	filter := c.filter
+	if c.opts.Order != "" {
+		opts.Order = c.opts.Order
+		opts.Offset = c.opts.Offset + c.read
+	} else {
+		opts.Order = "id"
+		if c.read == 0 {
+			opts.Offset = c.opts.Offset
+		} else {
+			filter = append([]any{[]any{"id", ">", c.lastID}}, c.filter...)
+		}
+	}
+	recs, err := c.o.SearchReadWithOptionsCtx(c.ctx, c.model, filter, c.fields, opts)
+	if err != nil {
+		return err
+	}
+	if len(recs) < limit {
+		c.done = true
+	}
+	if len(recs) > 0 {
+		if id, ok := recs[len(recs)-1]["id"].(float64); ok {
+			c.lastID = int(id)
+		}
+	}
+	c.page = recs
+	return nil
+}
@@ -0,0 +1,75 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	calls := 0
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		calls++
+		args := params["args"].([]any)
+		filter := args[5].([]any)[0].([]any)
+		kw := args[6].(map[string]any)
+		from := 0
+		if len(filter) > 0 {
+			from = int(filter[0].([]any)[2].(float64))
+		}
+		if offset, ok := kw["offset"].(float64); ok {
+			from += int(offset)
+		}
+		recs := []any{}
+		for id := from + 1; id <= 7 && len(recs) < int(kw["limit"].(float64)); id++ {
+			recs = append(recs, map[string]any{"id": float64(id)})
+		}
+		return recs
+	})
+	defer done()
+
+	patterns := []struct {
+		opts     SearchOptions
+		expected int
+		calls    int
+	}{
+		{SearchOptions{}, 7, 3},
+		{SearchOptions{Limit: 5}, 5, 2},
+		{SearchOptions{Offset: 2}, 5, 2},
+		{SearchOptions{Limit: 6}, 6, 2},
+	}
+	for i, pattern := range patterns {
+		calls = 0
+		c := o.SearchReadCursor("stock.move", nil, []string{"id"}, 3, pattern.opts)
+		n, last := 0, 0
+		for c.Next() {
+			id := int(c.Record()["id"].(float64))
+			if id <= last {
+				t.Errorf("\n[%d]: id %d after %d", i, id, last)
+			}
+			last = id
+			n++
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if n != pattern.expected || calls != pattern.calls {
+			t.Errorf("\n[%d]: expected %d records in %d calls, got %d in %d", i, pattern.expected, pattern.calls, n, calls)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Odoo date and datetime formats, datetimes are stored in UTC
+const (
+	DateFormat     = "2006-01-02"
+	DateTimeFormat = "2006-01-02 15:04:05"
+)
+
+// ParseDate parses an Odoo date
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(DateFormat, s, time.UTC)
+}
+
+// ParseDateTime parses an Odoo UTC datetime and returns it in loc, a date
+// only value has no timezone and stays at midnight UTC
+func ParseDateTime(s string, loc *time.Location) (time.Time, error) {
+	if len(s) == len(DateFormat) {
+		return ParseDate(s)
+	}
+	t, err := time.ParseInLocation(DateTimeFormat, s, time.UTC)
+	if err != nil {
+		return t, err
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t, nil
+}
+
+// FormatDate formats the calendar date of t
+func FormatDate(t time.Time) string {
+	return t.Format(DateFormat)
+}
+
+// FormatDateTime formats t converted to UTC
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(DateTimeFormat)
+}
+
+// Location returns the timezone of the tz key of the connection context,
+// or UTC
+func (o *Odoo) Location() *time.Location {
+	if tz, ok := o.Context["tz"].(string); ok && tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// Date value of a date field, the server sends false when empty
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the Date of the calendar day of t
+func NewDate(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// MarshalJSON encodes the date, or false when empty
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return jsonFalse, nil
+	}
+	return json.Marshal(FormatDate(d.Time))
+}
+
+// UnmarshalJSON decodes false or a date
+func (d *Date) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalTimeString(b)
+	if err != nil || s == "" {
+		*d = Date{}
+		return err
+	}
+	t, err := ParseDate(s)
+	*d = Date{t}
+	return err
+}
+
+// DateTime value of a datetime field, the server sends false when empty
+type DateTime struct {
+	time.Time
+}
+
+// MarshalJSON encodes the datetime in UTC, or false when empty
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if dt.IsZero() {
+		return jsonFalse, nil
+	}
+	return json.Marshal(FormatDateTime(dt.Time))
+}
+
+// UnmarshalJSON decodes false or a datetime in UTC
+func (dt *DateTime) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalTimeString(b)
+	if err != nil || s == "" {
+		*dt = DateTime{}
+		return err
+	}
+	t, err := ParseDateTime(s, time.UTC)
+	*dt = DateTime{t}
+	return err
+}
+
+// unmarshalTimeString returns the string in b, or "" for false
+func unmarshalTimeString(b []byte) (s string, err error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, jsonFalse) || bytes.Equal(b, []byte("null")) {
+		return "", nil
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateType     = reflect.TypeOf(Date{})
+	dateTimeType = reflect.TypeOf(DateTime{})
+)
+
+// decodeTime stores a date or datetime string in a time.Time, Date or
+// DateTime field, datetimes are converted to loc
+func decodeTime(value any, rv reflect.Value, loc *time.Location) (handled bool, err error) {
+	if rv.Type() != timeType && rv.Type() != dateType && rv.Type() != dateTimeType {
+		return false, nil
+	}
+	if value == false {
+		rv.Set(reflect.Zero(rv.Type()))
+		return true, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return true, fmt.Errorf("%w: cannot decode %T into %s", ErrDecode, value, rv.Type())
+	}
+	var t time.Time
+	if rv.Type() == dateType {
+		t, err = ParseDate(s)
+	} else {
+		t, err = ParseDateTime(s, loc)
+	}
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", ErrDecode, err)
+	}
+	switch rv.Type() {
+	case dateType:
+		rv.Set(reflect.ValueOf(Date{t}))
+	case dateTimeType:
+		rv.Set(reflect.ValueOf(DateTime{t}))
+	default:
+		rv.Set(reflect.ValueOf(t))
+	}
+	return true, nil
+}
@@ -0,0 +1,121 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type testMove struct {
+	Date      Date       `odoo:"date"`
+	DateDone  time.Time  `odoo:"date_done"`
+	Scheduled *time.Time `odoo:"scheduled_date"`
+	WriteDate DateTime   `odoo:"write_date"`
+}
+
+func TestDecodeTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	rec := map[string]any{"date": "2024-01-31", "date_done": "2024-01-31 13:45:00", "scheduled_date": false, "write_date": "2024-01-31 23:30:00"}
+	var got testMove
+	if err := DecodeIn(rec, &got, paris); err != nil {
+		t.Fatal(err)
+	}
+	if got.Date.Format(DateTimeFormat) != "2024-01-31 00:00:00" {
+		t.Errorf("unexpected date %v", got.Date)
+	}
+	if got.DateDone.Location() != paris || got.DateDone.Hour() != 14 || !got.DateDone.Equal(time.Date(2024, 1, 31, 13, 45, 0, 0, time.UTC)) {
+		t.Errorf("unexpected datetime %v", got.DateDone)
+	}
+	if got.Scheduled != nil {
+		t.Errorf("expected nil, got %v", got.Scheduled)
+	}
+	if got.WriteDate.In(paris).Day() != 1 {
+		t.Errorf("unexpected datetime %v", got.WriteDate)
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := DecodeIn(map[string]any{"date_done": "2024-01-31"}, &got, newYork); err != nil {
+		t.Fatal(err)
+	}
+	if got.DateDone.Format(DateTimeFormat) != "2024-01-31 00:00:00" {
+		t.Errorf("expected the date to keep its calendar day, got %v", got.DateDone)
+	}
+	if err := Decode(map[string]any{"date_done": "31/01/2024"}, &got); err == nil {
+		t.Errorf("expected an error for an invalid datetime")
+	}
+}
+
+func TestEncodeTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	done := time.Date(2024, 1, 31, 14, 45, 0, 0, paris)
+	record, err := Encode(testMove{Date: NewDate(done), DateDone: done, WriteDate: DateTime{done}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"date":"2024-01-31","date_done":"2024-01-31 13:45:00","scheduled_date":null,"write_date":"2024-01-31 13:45:00"}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	b, err = json.Marshal(normalize([]any{[]any{"date", "=", done}, []any{"date", "!=", time.Time{}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `[["date","=","2024-01-31 13:45:00"],["date","!=",false]]`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skip(err)
+	}
+	midnight := time.Date(2024, 1, 31, 0, 0, 0, 0, sydney)
+	got, err := FormatDomain([]any{[]any{"date", "=", Date{midnight}}, []any{"date", "=", NewDate(midnight)}, []any{"create_date", ">=", midnight}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "[('date', '=', '2024-01-31'), ('date', '=', '2024-01-31'), ('create_date', '>=', '2024-01-30 13:00:00')]"; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	record, err = Encode(testMove{Date: Date{midnight}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record["date"] != (Date{midnight}) {
+		t.Errorf("unexpected date %v", record["date"])
+	}
+	if b, _ := json.Marshal(record["date"]); string(b) != `"2024-01-31"` {
+		t.Errorf("expected the calendar date of a Sydney midnight, got %s", b)
+	}
+}
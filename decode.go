@@ -0,0 +1,357 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDecode error on a record value that does not fit its struct field
+var ErrDecode = errors.New("decode error")
+
+// structField is a field of a struct tagged with `odoo:"name"`
+type structField struct {
+	name      string
+	index     []int
+	omitempty bool
+}
+
+var structFieldsCache sync.Map // reflect.Type -> []structField
+
+// structFields returns the tagged fields of t, embedded structs are
+// flattened
+func structFields(t reflect.Type) []structField {
+	if v, ok := structFieldsCache.Load(t); ok {
+		return v.([]structField)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("odoo")
+		if !ok && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for _, ef := range structFields(f.Type) {
+				ef.index = append([]int{i}, ef.index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if !ok || tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			omitempty: opts == "omitempty",
+		})
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// StructFields returns the odoo field names tagged on the struct v points to
+// or is, for use as the fields list of SearchRead and Read
+func StructFields(v any) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for _, f := range structFields(t) {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// Decode stores the record rec in the struct pointed to by v. Odoo's false
+// for empty values decodes to the zero value or a nil pointer. Datetimes are
+// returned in UTC.
+func Decode(rec map[string]any, v any) error {
+	return DecodeIn(rec, v, time.UTC)
+}
+
+// DecodeIn stores the record rec in the struct pointed to by v, datetimes
+// are returned in loc
+func DecodeIn(rec map[string]any, v any, loc *time.Location) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a pointer to a struct, got %T", ErrDecode, v)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return decodeStruct(rec, rv.Elem(), loc)
+}
+
+func decodeStruct(rec map[string]any, rv reflect.Value, loc *time.Location) error {
+	for _, f := range structFields(rv.Type()) {
+		value, ok := rec[f.name]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(value, rv.FieldByIndex(f.index), loc); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeValue stores the JSON decoded value in rv
+func decodeValue(value any, rv reflect.Value, loc *time.Location) error {
+	if value == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if handled, err := decodeTime(value, rv, loc); handled {
+		return err
+	}
+	if rv.Addr().Type().Implements(unmarshalerType) {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDecode, err)
+		}
+		if err := rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+			return fmt.Errorf("%w: %v", ErrDecode, err)
+		}
+		return nil
+	}
+	// false is the empty value of a non boolean field
+	if value == false && rv.Kind() != reflect.Bool && rv.Kind() != reflect.Interface &&
+		!(rv.Kind() == reflect.Pointer && rv.Type().Elem().Kind() == reflect.Bool) {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	mismatch := fmt.Errorf("%w: cannot decode %T into %s", ErrDecode, value, rv.Type())
+	switch rv.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(rv.Type().Elem())
+		if err := decodeValue(value, elem.Elem(), loc); err != nil {
+			return err
+		}
+		rv.Set(elem)
+	case reflect.Interface:
+		if !reflect.TypeOf(value).AssignableTo(rv.Type()) {
+			return mismatch
+		}
+		rv.Set(reflect.ValueOf(value))
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch
+		}
+		rv.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) || rv.OverflowInt(int64(n)) {
+			return mismatch
+		}
+		rv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(float64)
+		if !ok || n < 0 || n != float64(uint64(n)) || rv.OverflowUint(uint64(n)) {
+			return mismatch
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(float64)
+		if !ok {
+			return mismatch
+		}
+		rv.SetFloat(n)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return decodeBinary(value, rv)
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch
+		}
+		s := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, s.Index(i), loc); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok || !reflect.TypeOf(m).AssignableTo(rv.Type()) {
+			return mismatch
+		}
+		rv.Set(reflect.ValueOf(m))
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return mismatch
+		}
+		return decodeStruct(m, rv, loc)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// decodeBinary stores the base64 content of a binary field in a []byte
+func decodeBinary(value any, rv reflect.Value) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%w: cannot decode %T into %s", ErrDecode, value, rv.Type())
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDecode, err)
+	}
+	rv.SetBytes(b)
+	return nil
+}
+
+// decodeRecords decodes recs into a slice of T
+func decodeRecords[T any](recs []map[string]any, loc *time.Location) ([]T, error) {
+	out := make([]T, len(recs))
+	for i, rec := range recs {
+		var target any = &out[i]
+		// a pointer T gets a new struct per record
+		if rv := reflect.ValueOf(&out[i]).Elem(); rv.Kind() == reflect.Pointer {
+			rv.Set(reflect.New(rv.Type().Elem()))
+			target = rv.Interface()
+		}
+		if err := DecodeIn(rec, target, loc); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// SearchReadInto records decoded into T, a struct or a pointer to one, the
+// fields are the odoo tags of T, datetimes are returned in the timezone of
+// the connection context
+func SearchReadInto[T any](o *Odoo, model string, filter []any, opts SearchOptions) ([]T, error) {
+	return SearchReadIntoCtx[T](context.Background(), o, model, filter, opts)
+}
+
+// SearchReadIntoCtx records decoded into T, the fields are the odoo tags of T
+func SearchReadIntoCtx[T any](ctx context.Context, o *Odoo, model string, filter []any, opts SearchOptions) ([]T, error) {
+	var zero T
+	recs, err := o.SearchReadWithOptionsCtx(ctx, model, filter, StructFields(&zero), opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](recs, o.Location())
+}
+
+// ReadInto records decoded into T, the fields are the odoo tags of T
+func ReadInto[T any](o *Odoo, model string, ids []int, kwargs ...map[string]any) ([]T, error) {
+	return ReadIntoCtx[T](context.Background(), o, model, ids, kwargs...)
+}
+
+// ReadIntoCtx records decoded into T, the fields are the odoo tags of T
+func ReadIntoCtx[T any](ctx context.Context, o *Odoo, model string, ids []int, kwargs ...map[string]any) ([]T, error) {
+	var zero T
+	recs, err := o.ReadCtx(ctx, model, ids, StructFields(&zero), kwargs...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](recs, o.Location())
+}
+
+// Encode returns the tagged fields of the struct v points to or is as the
+// values of a Create or Update. The id field, nil IDs fields and omitempty
+// fields holding their zero value are left out, IDs fields are set commands
+// and []byte fields are base64 encoded. time.Time fields are written as UTC
+// datetimes, use Date for date fields.
+func Encode(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("encode error: expected a struct, got %T", v)
+	}
+	record := map[string]any{}
+	for _, f := range structFields(rv.Type()) {
+		fv := rv.FieldByIndex(f.index)
+		if f.name == "id" || (f.omitempty && fv.IsZero()) {
+			continue
+		}
+		if ids, ok := fv.Interface().(IDs); ok {
+			if ids != nil {
+				record[f.name] = Commands{CommandSet(ids...)}
+			}
+			continue
+		}
+		record[f.name] = normalize(fv.Interface())
+	}
+	return record, nil
+}
+
+// normalize replaces the time.Time values nested in maps and lists by Odoo
+// UTC datetime strings and []byte values by base64 strings. A time.Time is
+// always a datetime, date fields take a Date so a midnight outside UTC is
+// not moved to the previous day.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		if v == nil {
+			return false
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		if v.IsZero() {
+			return false
+		}
+		return FormatDateTime(v)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return normalize(*v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = normalize(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,119 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testAudit struct {
+	CreateUID int `odoo:"create_uid"`
+}
+
+type testPartner struct {
+	testAudit
+	ID        int            `odoo:"id"`
+	Name      string         `odoo:"name"`
+	Email     string         `odoo:"email"`
+	Active    bool           `odoo:"active"`
+	Credit    float64        `odoo:"credit"`
+	ParentID  *int           `odoo:"parent_id"`
+	Phone     *string        `odoo:"phone"`
+	Blocked   *bool          `odoo:"is_blocked"`
+	ChildIDs  []int          `odoo:"child_ids"`
+	Raw       any            `odoo:"raw"`
+	Extra     map[string]any `odoo:"extra"`
+	Ignored   string         `odoo:"-"`
+	Untagged  string
+	unexposed string `odoo:"unexposed"`
+}
+
+func TestStructFields(t *testing.T) {
+	expected := []string{"create_uid", "id", "name", "email", "active", "credit", "parent_id", "phone", "is_blocked", "child_ids", "raw", "extra"}
+	if got := StructFields(testPartner{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	parent, blocked, unblocked := 3, true, false
+	patterns := []struct {
+		rec      map[string]any
+		expected testPartner
+		err      error
+	}{
+		{
+			map[string]any{"id": 7.0, "name": "Azure", "email": false, "active": true, "credit": 1.5, "parent_id": 3.0, "phone": false, "child_ids": []any{8.0, 9.0}, "raw": false, "create_uid": 2.0},
+			testPartner{testAudit: testAudit{CreateUID: 2}, ID: 7, Name: "Azure", Active: true, Credit: 1.5, ParentID: &parent, ChildIDs: []int{8, 9}, Raw: false},
+			nil,
+		},
+		{map[string]any{"extra": map[string]any{"a": 1.0}}, testPartner{Extra: map[string]any{"a": 1.0}}, nil},
+		{map[string]any{"is_blocked": false}, testPartner{Blocked: &unblocked}, nil},
+		{map[string]any{"is_blocked": true}, testPartner{Blocked: &blocked}, nil},
+		{map[string]any{"is_blocked": nil}, testPartner{}, nil},
+		{map[string]any{"id": "7"}, testPartner{}, ErrDecode},
+		{map[string]any{"id": 7.5}, testPartner{}, ErrDecode},
+		{map[string]any{"child_ids": []any{"x"}}, testPartner{}, ErrDecode},
+	}
+	for i, pattern := range patterns {
+		var got testPartner
+		err := Decode(pattern.rec, &got)
+		if !errors.Is(err, pattern.err) {
+			t.Errorf("\n[%d]: expected error %v, got %v", i, pattern.err, err)
+		}
+		if err == nil && !reflect.DeepEqual(got, pattern.expected) {
+			t.Errorf("\n[%d]: expected %+v, got %+v", i, pattern.expected, got)
+		}
+	}
+}
+
+func TestSearchReadInto(t *testing.T) {
+	var fields []any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		fields = params["args"].([]any)[6].(map[string]any)["fields"].([]any)
+		return []any{map[string]any{"id": 1.0, "name": "Admin", "parent_id": false}}
+	})
+	defer done()
+
+	recs, err := SearchReadInto[testPartner](o, "res.partner", nil, SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].ID != 1 || recs[0].Name != "Admin" || recs[0].ParentID != nil {
+		t.Errorf("unexpected records %+v", recs)
+	}
+	if len(fields) != len(StructFields(testPartner{})) {
+		t.Errorf("expected fields from tags, got %v", fields)
+	}
+
+	ptrs, err := SearchReadInto[*testPartner](o, "res.partner", nil, SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 1 || ptrs[0].ID != 1 || ptrs[0].Name != "Admin" {
+		t.Errorf("unexpected records %+v", ptrs)
+	}
+	if len(fields) != len(StructFields(testPartner{})) {
+		t.Errorf("expected fields from tags, got %v", fields)
+	}
+	if _, err := ReadInto[*testPartner](o, "res.partner", []int{1}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
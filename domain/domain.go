@@ -0,0 +1,336 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+
+// Package domain builds Odoo search domains as typed expressions
+//
+//	d := domain.Field("state").In("draft", "sent").And(domain.Field("amount_total").Gt(100))
+//	filter, err := domain.Build(d)
+//
+// Build validates the expression and returns the prefix notation []any taken
+// by SearchRead, Search and Count. The <> operator is left out on purpose, it
+// is a deprecated alias of != in Odoo.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalid error on an invalid domain expression
+var ErrInvalid = errors.New("invalid domain")
+
+// Operators of a leaf
+const (
+	Eq        = "="
+	Ne        = "!="
+	Gt        = ">"
+	Gte       = ">="
+	Lt        = "<"
+	Lte       = "<="
+	EqOrUnset = "=?"
+	Like      = "like"
+	NotLike   = "not like"
+	ILike     = "ilike"
+	NotILike  = "not ilike"
+	EqLike    = "=like"
+	EqILike   = "=ilike"
+	In        = "in"
+	NotIn     = "not in"
+	ChildOf   = "child_of"
+	ParentOf  = "parent_of"
+	Any       = "any"
+	NotAny    = "not any"
+)
+
+// Node is a domain expression
+type Node interface {
+	// Prefix returns the expression in prefix notation without validation
+	Prefix() []any
+	// Validate checks the operators and values of the expression
+	Validate() error
+	And(nodes ...Node) Node
+	Or(nodes ...Node) Node
+	Not() Node
+}
+
+// Build validates n and returns its prefix notation, an empty And matches
+// every record
+func Build(n Node) ([]any, error) {
+	if n == nil {
+		return nil, fmt.Errorf("%w: nil node", ErrInvalid)
+	}
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return subdomain(n), nil
+}
+
+// subdomain returns the prefix notation of n, [] for an empty And
+func subdomain(n Node) []any {
+	if and, ok := n.(And); ok && len(and) == 0 {
+		return []any{}
+	}
+	return n.Prefix()
+}
+
+// MustBuild is Build panicking on an invalid expression, for expressions
+// known to be valid
+func MustBuild(n Node) []any {
+	d, err := Build(n)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Leaf compares a field with a value
+type Leaf struct {
+	Field    string
+	Operator string
+	Value    any
+}
+
+// And matches the records matching every node, an empty And matches every
+// record
+type And []Node
+
+// Or matches the records matching any node, an empty Or matches no record
+type Or []Node
+
+// NotNode matches the records not matching Node
+type NotNode struct {
+	Node Node
+}
+
+// trueLeaf and falseLeaf are Odoo's TRUE_LEAF and FALSE_LEAF
+var (
+	trueLeaf  = []any{1, "=", 1}
+	falseLeaf = []any{0, "=", 1}
+)
+
+func (l Leaf) Prefix() []any {
+	value := l.Value
+	if n, ok := value.(Node); ok {
+		value = subdomain(n)
+	}
+	return []any{[]any{l.Field, l.Operator, value}}
+}
+
+func (a And) Prefix() []any {
+	return prefix("&", trueLeaf, a)
+}
+
+func (o Or) Prefix() []any {
+	return prefix("|", falseLeaf, o)
+}
+
+func (n NotNode) Prefix() []any {
+	return append([]any{"!"}, n.Node.Prefix()...)
+}
+
+// prefix returns len(nodes)-1 operators followed by the nodes
+func prefix(op string, empty []any, nodes []Node) []any {
+	if len(nodes) == 0 {
+		return []any{empty}
+	}
+	var out []any
+	for i := 1; i < len(nodes); i++ {
+		out = append(out, op)
+	}
+	for _, n := range nodes {
+		out = append(out, n.Prefix()...)
+	}
+	return out
+}
+
+func (l Leaf) And(nodes ...Node) Node    { return And(append([]Node{l}, nodes...)) }
+func (a And) And(nodes ...Node) Node     { return append(append(And{}, a...), nodes...) }
+func (o Or) And(nodes ...Node) Node      { return And(append([]Node{o}, nodes...)) }
+func (n NotNode) And(nodes ...Node) Node { return And(append([]Node{n}, nodes...)) }
+
+func (l Leaf) Or(nodes ...Node) Node    { return Or(append([]Node{l}, nodes...)) }
+func (a And) Or(nodes ...Node) Node     { return Or(append([]Node{a}, nodes...)) }
+func (o Or) Or(nodes ...Node) Node      { return append(append(Or{}, o...), nodes...) }
+func (n NotNode) Or(nodes ...Node) Node { return Or(append([]Node{n}, nodes...)) }
+
+func (l Leaf) Not() Node { return NotNode{l} }
+func (a And) Not() Node  { return NotNode{a} }
+func (o Or) Not() Node   { return NotNode{o} }
+
+// Not of a NotNode returns its operand, a NotNode without operand stays
+// itself so Validate reports it
+func (n NotNode) Not() Node {
+	if n.Node == nil {
+		return n
+	}
+	return n.Node
+}
+
+// Not negates n
+func Not(n Node) Node {
+	if n == nil {
+		return NotNode{}
+	}
+	return n.Not()
+}
+
+func (a And) Validate() error {
+	return validateAll(a)
+}
+
+func (o Or) Validate() error {
+	return validateAll(o)
+}
+
+func (n NotNode) Validate() error {
+	if n.Node == nil {
+		return fmt.Errorf("%w: not without operand", ErrInvalid)
+	}
+	return n.Node.Validate()
+}
+
+func validateAll(nodes []Node) error {
+	for _, n := range nodes {
+		if n == nil {
+			return fmt.Errorf("%w: nil node", ErrInvalid)
+		}
+		if err := n.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the operator is known and the value fits it
+func (l Leaf) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s %s: %s", ErrInvalid, l.Field, l.Operator, fmt.Sprintf(format, args...))
+	}
+	if strings.TrimSpace(l.Field) == "" {
+		return fmt.Errorf("%w: empty field name", ErrInvalid)
+	}
+	list := isList(l.Value)
+	switch l.Operator {
+	case In, NotIn:
+		if !list {
+			return invalid("expected a list, got %T", l.Value)
+		}
+	case Like, NotLike, ILike, NotILike, EqLike, EqILike:
+		if _, ok := l.Value.(string); !ok {
+			return invalid("expected a string, got %T", l.Value)
+		}
+	case Gt, Gte, Lt, Lte:
+		if l.Value == nil || l.Value == false || list {
+			return invalid("expected a comparable value, got %v", l.Value)
+		}
+	case ChildOf, ParentOf:
+		if l.Value == nil || l.Value == false || (list && reflect.ValueOf(l.Value).Len() == 0) {
+			return invalid("expected ids, got %v", l.Value)
+		}
+	case Any, NotAny:
+		n, ok := l.Value.(Node)
+		if !ok {
+			return invalid("expected a domain, got %T", l.Value)
+		}
+		return n.Validate()
+	case Eq, Ne, EqOrUnset:
+		if list {
+			return invalid("expected a single value, use in or not in for lists")
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalid, l.Operator)
+	}
+	return nil
+}
+
+// isList reports whether v is a slice or an array, []byte excepted
+func isList(v any) bool {
+	rv := reflect.ValueOf(v)
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+// toList converts a slice or an array to []any
+func toList(v any) []any {
+	rv := reflect.ValueOf(v)
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// FieldRef starts a Leaf on a field, dotted paths such as
+// partner_id.country_id.code are accepted
+type FieldRef string
+
+// Field returns a FieldRef on name
+func Field(name string) FieldRef {
+	return FieldRef(name)
+}
+
+func (f FieldRef) leaf(op string, value any) Leaf {
+	return Leaf{Field: string(f), Operator: op, Value: value}
+}
+
+func (f FieldRef) Eq(value any) Leaf          { return f.leaf(Eq, value) }
+func (f FieldRef) Ne(value any) Leaf          { return f.leaf(Ne, value) }
+func (f FieldRef) Gt(value any) Leaf          { return f.leaf(Gt, value) }
+func (f FieldRef) Gte(value any) Leaf         { return f.leaf(Gte, value) }
+func (f FieldRef) Lt(value any) Leaf          { return f.leaf(Lt, value) }
+func (f FieldRef) Lte(value any) Leaf         { return f.leaf(Lte, value) }
+func (f FieldRef) EqOrUnset(value any) Leaf   { return f.leaf(EqOrUnset, value) }
+func (f FieldRef) Like(value string) Leaf     { return f.leaf(Like, value) }
+func (f FieldRef) NotLike(value string) Leaf  { return f.leaf(NotLike, value) }
+func (f FieldRef) ILike(value string) Leaf    { return f.leaf(ILike, value) }
+func (f FieldRef) NotILike(value string) Leaf { return f.leaf(NotILike, value) }
+func (f FieldRef) EqLike(value string) Leaf   { return f.leaf(EqLike, value) }
+func (f FieldRef) EqILike(value string) Leaf  { return f.leaf(EqILike, value) }
+
+// In matches one of values, a single slice argument is used as the list
+func (f FieldRef) In(values ...any) Leaf { return f.leaf(In, listArgs(values)) }
+
+// NotIn matches none of values, a single slice argument is used as the list
+func (f FieldRef) NotIn(values ...any) Leaf { return f.leaf(NotIn, listArgs(values)) }
+
+func (f FieldRef) ChildOf(ids ...int) Leaf  { return f.leaf(ChildOf, ids) }
+func (f FieldRef) ParentOf(ids ...int) Leaf { return f.leaf(ParentOf, ids) }
+
+// Any matches the records whose many2one, one2many or many2many field has a
+// record matching n
+func (f FieldRef) Any(n Node) Leaf { return f.leaf(Any, n) }
+
+// NotAny matches the records whose relational field has no record matching n
+func (f FieldRef) NotAny(n Node) Leaf { return f.leaf(NotAny, n) }
+
+// IsSet matches the records where the field is set
+func (f FieldRef) IsSet() Leaf { return f.leaf(Ne, false) }
+
+// IsNotSet matches the records where the field is not set
+func (f FieldRef) IsNotSet() Leaf { return f.leaf(Eq, false) }
+
+func listArgs(values []any) []any {
+	if len(values) == 1 && isList(values[0]) {
+		return toList(values[0])
+	}
+	if values == nil {
+		return []any{}
+	}
+	return values
+}
@@ -0,0 +1,99 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var buildPatterns = []struct {
+	node     Node
+	expected []any
+	err      error
+}{
+	{And{}, []any{}, nil},
+	{Field("name").Eq("Azure"), []any{[]any{"name", "=", "Azure"}}, nil},
+	{
+		Field("state").In("draft", "sent").And(Field("amount_total").Gt(100)),
+		[]any{"&", []any{"state", "in", []any{"draft", "sent"}}, []any{"amount_total", ">", 100}},
+		nil,
+	},
+	{
+		Field("state").In([]string{"draft", "sent"}).Or(Field("a").Eq(1), Field("b").Eq(2)),
+		[]any{"|", "|", []any{"state", "in", []any{"draft", "sent"}}, []any{"a", "=", 1}, []any{"b", "=", 2}},
+		nil,
+	},
+	{
+		Not(Field("a").Eq(1).Or(Field("b").IsSet())).And(Field("partner_id.country_id.code").ILike("us")),
+		[]any{"&", "!", "|", []any{"a", "=", 1}, []any{"b", "!=", false}, []any{"partner_id.country_id.code", "ilike", "us"}},
+		nil,
+	},
+	{Field("a").Eq(1).And(Field("b").Eq(2)).And(Field("c").Eq(3)), []any{"&", "&", []any{"a", "=", 1}, []any{"b", "=", 2}, []any{"c", "=", 3}}, nil},
+	{Field("a").Eq(1).Not().Not(), []any{[]any{"a", "=", 1}}, nil},
+	{Or{}, []any{[]any{0, "=", 1}}, nil},
+	{Field("parent_id").ChildOf(1, 2), []any{[]any{"parent_id", "child_of", []int{1, 2}}}, nil},
+	{Field("id").In(), []any{[]any{"id", "in", []any{}}}, nil},
+	{
+		Field("order_line").Any(Field("product_id").Eq(1).Or(Field("qty").Gt(2))),
+		[]any{[]any{"order_line", "any", []any{"|", []any{"product_id", "=", 1}, []any{"qty", ">", 2}}}},
+		nil,
+	},
+	{Field("child_ids").NotAny(And{}), []any{[]any{"child_ids", "not any", []any{}}}, nil},
+	{Field("state").In("draft"), []any{[]any{"state", "in", []any{"draft"}}}, nil},
+	{Field("").Eq(1), nil, ErrInvalid},
+	{Field("state").Eq([]string{"draft"}), nil, ErrInvalid},
+	{Leaf{"state", "in", "draft"}, nil, ErrInvalid},
+	{Leaf{"name", "like", 1}, nil, ErrInvalid},
+	{Field("amount").Gt(nil), nil, ErrInvalid},
+	{Leaf{"name", "~", "x"}, nil, ErrInvalid},
+	{Field("a").Eq(1).And(Leaf{"b", "in", 1}), nil, ErrInvalid},
+	{NotNode{}, nil, ErrInvalid},
+	{nil, nil, ErrInvalid},
+	{NotNode{}.Not(), nil, ErrInvalid},
+	{Not(nil), nil, ErrInvalid},
+	{Field("parent_id").ChildOf(), nil, ErrInvalid},
+	{Leaf{"parent_id", "parent_of", []int{}}, nil, ErrInvalid},
+	{Leaf{"order_line", "any", []any{}}, nil, ErrInvalid},
+	{Field("order_line").Any(Field("qty").Gt(nil)), nil, ErrInvalid},
+	{Leaf{"name", "<>", "x"}, nil, ErrInvalid},
+}
+
+func TestBuild(t *testing.T) {
+	for i, pattern := range buildPatterns {
+		got, err := Build(pattern.node)
+		if !errors.Is(err, pattern.err) || (err == nil) != (pattern.err == nil) {
+			t.Errorf("\n[%d]: expected error %v, got %v", i, pattern.err, err)
+		}
+		if !reflect.DeepEqual(got, pattern.expected) {
+			t.Errorf("\n[%d]: expected %v, got %v", i, pattern.expected, got)
+		}
+	}
+}
+
+func TestAndDoesNotAlias(t *testing.T) {
+	base := And{Field("a").Eq(1)}
+	base = append(base, Field("b").Eq(2))[:1:2]
+	x := base.And(Field("x").Eq(1))
+	y := base.And(Field("y").Eq(1))
+	if reflect.DeepEqual(x, y) {
+		t.Errorf("expected distinct domains, got %v and %v", x, y)
+	}
+}
@@ -0,0 +1,175 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OdooError is the error object returned by the Odoo server
+type OdooError struct {
+	Code        int
+	Message     string
+	Name        string // exception class, e.g. odoo.exceptions.AccessError
+	DataMessage string
+	Debug       string // server traceback
+	Arguments   []any
+	Context     map[string]any
+}
+
+func (e *OdooError) Error() string {
+	if e.DataMessage == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.DataMessage
+}
+
+// Exception returns the exception class name without its module path
+func (e *OdooError) Exception() string {
+	return e.Name[strings.LastIndex(e.Name, ".")+1:]
+}
+
+// newOdooError decodes the error member of a JSON-RPC response
+func newOdooError(v any) *OdooError {
+	e := &OdooError{}
+	m, _ := v.(map[string]any)
+	if code, ok := m["code"].(float64); ok {
+		e.Code = int(code)
+	}
+	e.Message, _ = m["message"].(string)
+	data, _ := m["data"].(map[string]any)
+	e.Name, _ = data["name"].(string)
+	e.DataMessage, _ = data["message"].(string)
+	e.Debug, _ = data["debug"].(string)
+	e.Arguments, _ = data["arguments"].([]any)
+	e.Context, _ = data["context"].(map[string]any)
+	if e.Message == "" && e.DataMessage == "" {
+		e.Message = "unknown odoo error"
+	}
+	return e
+}
+
+// userErrors are the exceptions deriving from odoo.exceptions.UserError
+var userErrors = []string{"UserError", "AccessError", "MissingError", "ValidationError", "RedirectWarning", "Warning"}
+
+// isException reports whether err is an OdooError raised by one of the
+// exception classes
+func isException(err error, classes ...string) bool {
+	var e *OdooError
+	if !errors.As(err, &e) {
+		return false
+	}
+	name := e.Exception()
+	for _, class := range classes {
+		if name == class {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAccessError reports whether err is an odoo.exceptions.AccessError
+func IsAccessError(err error) bool {
+	return isException(err, "AccessError")
+}
+
+// IsAccessDenied reports whether err is an odoo.exceptions.AccessDenied
+func IsAccessDenied(err error) bool {
+	return isException(err, "AccessDenied")
+}
+
+// IsSessionExpired reports whether err is an expired web session
+func IsSessionExpired(err error) bool {
+	var e *OdooError
+	return errors.As(err, &e) && (e.Code == 100 || e.Exception() == "SessionExpiredException")
+}
+
+// IsAuthError reports whether err is an expired session or rejected
+// credentials, after which the client logs in again
+func IsAuthError(err error) bool {
+	return IsAccessDenied(err) || IsSessionExpired(err)
+}
+
+// IsValidationError reports whether err is an odoo.exceptions.ValidationError
+func IsValidationError(err error) bool {
+	return isException(err, "ValidationError")
+}
+
+// IsMissingError reports whether err is an odoo.exceptions.MissingError
+func IsMissingError(err error) bool {
+	return isException(err, "MissingError")
+}
+
+// IsUserError reports whether err is an odoo.exceptions.UserError or one of
+// its subclasses
+func IsUserError(err error) bool {
+	return isException(err, userErrors...)
+}
+
+// maxErrorBody bounds the response body kept in a TransportError
+const maxErrorBody = 512
+
+// TransportError is returned when the server does not answer with a JSON-RPC
+// payload, e.g. an HTTP error status or a proxy error page
+type TransportError struct {
+	StatusCode  int
+	Status      string
+	ContentType string
+	Body        string        // leading bytes of the response body
+	RetryAfter  time.Duration // Retry-After of a 429 or 503 response
+	Err         error
+}
+
+func (e *TransportError) Error() string {
+	msg := "unexpected response"
+	if e.Status != "" {
+		msg += " " + e.Status
+	}
+	if e.ContentType != "" {
+		msg += " (" + e.ContentType + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// newTransportError captures the status and a snippet of body of resp
+func newTransportError(resp *http.Response, body []byte, err error) *TransportError {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return &TransportError{
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        strings.TrimSpace(string(body)),
+		RetryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Err:         err,
+	}
+}
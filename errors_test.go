@@ -0,0 +1,89 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOdooError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":200,"message":"Odoo Server Error","data":{"name":"odoo.exceptions.AccessError","debug":"Traceback","message":"denied","arguments":["denied"],"context":{}}}}`)
+	}))
+	defer srv.Close()
+
+	o := &Odoo{URL: srv.URL + "/jsonrpc"}
+	_, err := o.Call("object", "execute_kw")
+	var e *OdooError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *OdooError, got %v", err)
+	}
+	if e.Code != 200 || e.Name != "odoo.exceptions.AccessError" || e.Debug != "Traceback" || len(e.Arguments) != 1 {
+		t.Errorf("unexpected error fields: %+v", e)
+	}
+	if err.Error() != "Odoo Server Error: denied" {
+		t.Errorf("expected %q, got %q", "Odoo Server Error: denied", err)
+	}
+	if !IsAccessError(err) || !IsUserError(err) || IsValidationError(err) || IsMissingError(err) {
+		t.Errorf("unexpected classification of %s", e.Name)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	patterns := []struct {
+		status      int
+		contentType string
+		body        string
+		odooError   bool
+	}{
+		{http.StatusBadGateway, "text/html", "<html><body>502 Bad Gateway</body></html>", false},
+		{http.StatusOK, "text/html", "<html><body>Maintenance</body></html>", false},
+		{http.StatusOK, "application/json", `{"jsonrpc":"2.0","id":1,"result":`, false},
+		{http.StatusOK, "application/json", `null`, false},
+		{http.StatusOK, "application/json", `{"jsonrpc":"2.0","id":1,"error":{"message":"Odoo Server Error"}}`, true},
+		{http.StatusOK, "application/json", `{"jsonrpc":"2.0","id":1,"error":"broken"}`, true},
+	}
+	for i, pattern := range patterns {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", pattern.contentType)
+			w.WriteHeader(pattern.status)
+			fmt.Fprint(w, pattern.body)
+		}))
+		o := &Odoo{URL: srv.URL + "/jsonrpc"}
+		_, err := o.Call("common", "version")
+		srv.Close()
+
+		var oe *OdooError
+		var te *TransportError
+		switch {
+		case pattern.odooError:
+			if !errors.As(err, &oe) {
+				t.Errorf("\n[%d]: expected *OdooError, got %v", i, err)
+			}
+		case !errors.As(err, &te):
+			t.Errorf("\n[%d]: expected *TransportError, got %v", i, err)
+		case te.StatusCode != pattern.status || te.ContentType != pattern.contentType:
+			t.Errorf("\n[%d]: expected %d %s, got %d %s", i, pattern.status, pattern.contentType, te.StatusCode, te.ContentType)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// MatchDomain reports whether record matches filter, in prefix notation or
+// grouped as returned by SearchDomain. The operators follow Odoo's
+// semantics: unset fields hold false, many2one [id, name] pairs compare by id
+// or by name for string values, x2many id lists match when one id does and
+// negative operators match unset fields. child_of, parent_of, any and not
+// any need the server and return an error.
+func MatchDomain(filter []any, record map[string]any) (bool, error) {
+	flat := prefixDomain(normalize(filter).([]any))
+	match := true
+	for i := 0; i < len(flat); {
+		ok, n, err := matchTerm(flat[i:], record)
+		if err != nil {
+			return false, err
+		}
+		match = match && ok
+		i += n
+	}
+	return match, nil
+}
+
+// matchTerm evaluates the term at the head of flat, it returns the number of
+// elements used
+func matchTerm(flat []any, record map[string]any) (match bool, n int, err error) {
+	switch op := flat[0]; op {
+	case "!":
+		if len(flat) < 2 {
+			return false, 0, fmt.Errorf("%w: missing operand of %q", ErrDomain, op)
+		}
+		match, n, err = matchTerm(flat[1:], record)
+		return !match, n + 1, err
+	case "&", "|":
+		n = 1
+		results := [2]bool{}
+		for i := range results {
+			if n >= len(flat) {
+				return false, 0, fmt.Errorf("%w: missing operand of %q", ErrDomain, op)
+			}
+			ok, used, err := matchTerm(flat[n:], record)
+			if err != nil {
+				return false, 0, err
+			}
+			results[i] = ok
+			n += used
+		}
+		if op == "&" {
+			return results[0] && results[1], n, nil
+		}
+		return results[0] || results[1], n, nil
+	}
+	match, err = matchLeaf(flat[0], record)
+	return match, 1, err
+}
+
+// matchLeaf evaluates a (field, operator, value) leaf
+func matchLeaf(term any, record map[string]any) (bool, error) {
+	leaf, ok := term.([]any)
+	if !ok || len(leaf) != 3 {
+		return false, fmt.Errorf("%w: expected an operator or a leaf, got %v", ErrDomain, term)
+	}
+	op, ok := leaf[1].(string)
+	if !ok {
+		return false, fmt.Errorf("%w: invalid operator %v", ErrDomain, leaf[1])
+	}
+	op = strings.ToLower(op)
+	value := leaf[2]
+
+	switch field := leaf[0].(type) {
+	case int, float64:
+		// TRUE_LEAF (1, '=', 1) and FALSE_LEAF (0, '=', 1)
+		return compareValues(field, op, value)
+	case string:
+		v, err := fieldValue(record, field)
+		if err != nil {
+			return false, err
+		}
+		return compareValues(v, op, value)
+	default:
+		return false, fmt.Errorf("%w: invalid field %v", ErrDomain, leaf[0])
+	}
+}
+
+// fieldValue returns the value of a field, dotted paths are followed through
+// nested maps
+func fieldValue(record map[string]any, field string) (any, error) {
+	if v, ok := record[field]; ok {
+		return v, nil
+	}
+	var v any = record
+	for _, name := range strings.Split(field, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot follow %s in the record", ErrDomain, field)
+		}
+		if v, ok = m[name]; !ok {
+			return nil, fmt.Errorf("%w: field %s not in the record", ErrDomain, field)
+		}
+	}
+	return v, nil
+}
+
+// isUnset reports whether v is an empty field value
+func isUnset(v any) bool {
+	if v == nil || v == false {
+		return true
+	}
+	if l, ok := v.([]any); ok {
+		return len(l) == 0
+	}
+	return false
+}
+
+// compareValues applies op to the record value v and the domain value
+func compareValues(v any, op string, value any) (bool, error) {
+	// many2one pairs compare by id, or by display name against strings
+	if pair, ok := v.([]any); ok && len(pair) == 2 {
+		if _, isName := pair[1].(string); isName {
+			if _, ok := value.(string); ok {
+				v = pair[1]
+			} else {
+				v = pair[0]
+			}
+		}
+	}
+	// x2many id lists match when one of the ids does
+	if ids, ok := v.([]any); ok && len(ids) > 0 {
+		negative := op == "!=" || op == "<>" || op == "not in" || op == "not like" || op == "not ilike"
+		for _, id := range ids {
+			match, err := compareValues(id, op, value)
+			if err != nil {
+				return false, err
+			}
+			if match != negative {
+				return !negative, nil
+			}
+		}
+		return negative, nil
+	}
+
+	switch op {
+	case "=?":
+		if isUnset(value) {
+			return true, nil
+		}
+		return equalValues(v, value), nil
+	case "=":
+		return equalValues(v, value), nil
+	case "!=", "<>":
+		return !equalValues(v, value), nil
+	case "in", "not in":
+		list, ok := toAnyList(value)
+		if !ok {
+			return false, fmt.Errorf("%w: %s expects a list, got %T", ErrDomain, op, value)
+		}
+		found := false
+		for _, item := range list {
+			if equalValues(v, item) {
+				found = true
+			}
+		}
+		return found == (op == "in"), nil
+	case "<", "<=", ">", ">=":
+		if isUnset(v) {
+			return false, nil
+		}
+		c, ok := compareOrdered(v, value)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "like", "ilike", "not like", "not ilike", "=like", "=ilike":
+		pattern, ok := value.(string)
+		if !ok {
+			return false, fmt.Errorf("%w: %s expects a string, got %T", ErrDomain, op, value)
+		}
+		negative := strings.HasPrefix(op, "not ")
+		if isUnset(v) {
+			return negative, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			s = fmt.Sprint(v)
+		}
+		if !strings.HasPrefix(op, "=") {
+			pattern = "%" + pattern + "%"
+		}
+		match, err := sqlLike(pattern, s, strings.Contains(op, "ilike"))
+		return match != negative, err
+	case "child_of", "parent_of", "any", "not any":
+		return false, fmt.Errorf("%w: %s cannot be evaluated on a record", ErrDomain, op)
+	default:
+		return false, fmt.Errorf("%w: invalid operator %q", ErrDomain, op)
+	}
+}
+
+// equalValues compares numbers by value and false with unset values
+func equalValues(a, b any) bool {
+	if isUnset(a) || isUnset(b) {
+		return isUnset(a) && isUnset(b)
+	}
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareOrdered compares numbers or strings
+func compareOrdered(a, b any) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		switch {
+		case !ok:
+			return 0, false
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	sa, ok := a.(string)
+	sb, ok2 := b.(string)
+	if !ok || !ok2 {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func toAnyList(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, true
+}
+
+// sqlLike matches s against a SQL LIKE pattern, % matches any run of
+// characters and _ a single one, a backslash escapes them
+func sqlLike(pattern, s string, fold bool) (bool, error) {
+	var b strings.Builder
+	b.WriteString(`^`)
+	if fold {
+		b.WriteString(`(?i)`)
+	}
+	b.WriteString(`(?s)`)
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(`.*`)
+		case r == '_':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDomain, err)
+	}
+	return re.MatchString(s), nil
+}
@@ -0,0 +1,114 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"testing"
+)
+
+var matchRecord = map[string]any{
+	"id":         7.0,
+	"name":       "Azure Interior",
+	"email":      false,
+	"active":     true,
+	"credit":     150.5,
+	"date":       "2024-01-31",
+	"partner_id": []any{3.0, "Deco Addict"},
+	"parent_id":  false,
+	"tag_ids":    []any{4.0, 5.0},
+	"child_ids":  []any{},
+	"country":    map[string]any{"code": "US"},
+}
+
+var matchDomainPatterns = []struct {
+	domain   string
+	expected bool
+	err      error
+}{
+	{"[]", true, nil},
+	{"[('id', '=', 7)]", true, nil},
+	{"[('id', '!=', 7)]", false, nil},
+	{"[('name', '=', 'Azure Interior'), ('active', '=', True)]", true, nil},
+	{"[('email', '=', False)]", true, nil},
+	{"[('email', '!=', False)]", false, nil},
+	{"[('email', '=', None)]", true, nil},
+	{"[('email', 'ilike', 'x')]", false, nil},
+	{"[('email', 'not ilike', 'x')]", true, nil},
+	{"[('email', '>', 'a')]", false, nil},
+	{"[('email', 'not in', ['a@b.c'])]", true, nil},
+	{"[('email', 'in', [False])]", true, nil},
+	{"[('active', '=', False)]", false, nil},
+	{"[('credit', '>=', 150.5), ('credit', '<', 200)]", true, nil},
+	{"[('date', '>', '2024-01-01'), ('date', '<=', '2024-01-31')]", true, nil},
+	{"[('name', 'like', 'Interior')]", true, nil},
+	{"[('name', 'like', 'interior')]", false, nil},
+	{"[('name', 'ilike', 'interior')]", true, nil},
+	{"[('name', 'not ilike', 'interior')]", false, nil},
+	{"[('name', '=like', 'Azure%')]", true, nil},
+	{"[('name', '=like', 'Azure')]", false, nil},
+	{"[('name', '=ilike', 'azure_interior')]", true, nil},
+	{"[('name', '=like', '100\\\\%')]", false, nil},
+	{"[('partner_id', '=', 3)]", true, nil},
+	{"[('partner_id', 'in', [1, 2])]", false, nil},
+	{"[('partner_id', 'ilike', 'deco')]", true, nil},
+	{"[('partner_id', '=', 'Deco Addict')]", true, nil},
+	{"[('parent_id', '=', False)]", true, nil},
+	{"[('tag_ids', '=', 5)]", true, nil},
+	{"[('tag_ids', 'in', [1, 4])]", true, nil},
+	{"[('tag_ids', 'not in', [4])]", false, nil},
+	{"[('tag_ids', '!=', 6)]", true, nil},
+	{"[('child_ids', '=', False)]", true, nil},
+	{"[('country.code', '=', 'US')]", true, nil},
+	{"[('name', '=?', False)]", true, nil},
+	{"[('name', '=?', 'Other')]", false, nil},
+	{"['|', ('id', '=', 1), ('id', '=', 7)]", true, nil},
+	{"['&', ('id', '=', 1), ('id', '=', 7)]", false, nil},
+	{"['!', ('id', '=', 1)]", true, nil},
+	{"['|', '!', ('id', '=', 7), '&', ('active', '=', True), ('credit', '>', 100)]", true, nil},
+	{"[(1, '=', 1)]", true, nil},
+	{"[(0, '=', 1)]", false, nil},
+	{"[('missing', '=', 1)]", false, ErrDomain},
+	{"[('parent_id', 'child_of', 1)]", false, ErrDomain},
+	{"[('id', 'in', 7)]", false, ErrDomain},
+	{"[('id', 'like', 7)]", false, ErrDomain},
+}
+
+func TestMatchDomain(t *testing.T) {
+	for i, pattern := range matchDomainPatterns {
+		filter, err := SearchDomain(pattern.domain)
+		if err != nil {
+			t.Fatalf("\n[%d]: %v", i, err)
+		}
+		got, err := MatchDomain(filter, matchRecord)
+		if !errors.Is(err, pattern.err) || (err == nil) != (pattern.err == nil) {
+			t.Errorf("\n[%d]: %s expected error %v, got %v", i, pattern.domain, pattern.err, err)
+		}
+		if got != pattern.expected {
+			t.Errorf("\n[%d]: %s expected %v, got %v", i, pattern.domain, pattern.expected, got)
+		}
+	}
+
+	flat := []any{"|", []any{"id", "=", 1}, []any{"id", "=", 7.0}}
+	if got, err := MatchDomain(flat, matchRecord); err != nil || !got {
+		t.Errorf("expected the prefix domain to match, got %v %v", got, err)
+	}
+	if _, err := MatchDomain([]any{"&", []any{"id", "=", 1}}, matchRecord); !errors.Is(err, ErrDomain) {
+		t.Errorf("expected ErrDomain, got %v", err)
+	}
+}
@@ -19,13 +19,16 @@ package odoojrpc
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
-	"strings"
+	"net/url"
 	"time"
 )
 
@@ -40,6 +43,45 @@ type Odoo struct {
 	Schema   string `default:"http"`
 	URL      string
 	UID      int
+
+	// HTTPClient is used as-is when set, otherwise a pooled client is built
+	// from Transport, Timeout, Proxy and reused for every call. The client
+	// and the limits below are built on first use, fields assigned directly
+	// afterwards apply from the next Init, the With setters apply at once to
+	// o and the copies sharing its connection
+	HTTPClient *http.Client
+	Transport  http.RoundTripper
+	Timeout    time.Duration
+	Proxy      *url.URL
+	Headers    map[string]string
+
+	// RateLimit caps the requests per second with bursts of RateBurst, and
+	// MaxInFlight the concurrent requests, of every copy sharing the
+	// connection, zero disables them
+	RateLimit   float64
+	RateBurst   int
+	MaxInFlight int
+
+	// TLSConfig is cloned as the base TLS configuration of the pooled
+	// transport, the fields below are applied on top of it
+	TLSConfig          *tls.Config
+	RootCAs            *x509.CertPool
+	Certificates       []tls.Certificate
+	MinTLSVersion      uint16
+	InsecureSkipVerify bool
+
+	// Auth selects password or session authentication
+	Auth AuthMode
+	// CredentialProvider takes precedence over Username and Password
+	CredentialProvider CredentialProvider
+	// RetryPolicy of the model calls, nil disables retries
+	RetryPolicy RetryPolicy
+
+	// Context is merged into the context of every model call, per call
+	// context keys take precedence
+	Context map[string]any
+
+	st *conn
 }
 
 func (o *Odoo) WithHostname(hostname string) *Odoo {
@@ -72,6 +114,146 @@ func (o *Odoo) WithSchema(schema string) *Odoo {
 	return o
 }
 
+func (o *Odoo) WithHTTPClient(client *http.Client) *Odoo {
+	o.HTTPClient = client
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithTransport(transport http.RoundTripper) *Odoo {
+	o.Transport = transport
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithTimeout(timeout time.Duration) *Odoo {
+	o.Timeout = timeout
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithProxy(proxy *url.URL) *Odoo {
+	o.Proxy = proxy
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithHeader(key, value string) *Odoo {
+	if o.Headers == nil {
+		o.Headers = map[string]string{}
+	}
+	o.Headers[key] = value
+	return o
+}
+
+func (o *Odoo) WithRateLimit(perSecond float64, burst int) *Odoo {
+	o.RateLimit = perSecond
+	o.RateBurst = burst
+	o.reconfigureLimiter()
+	return o
+}
+
+func (o *Odoo) WithMaxInFlight(n int) *Odoo {
+	o.MaxInFlight = n
+	o.reconfigureLimiter()
+	return o
+}
+
+func (o *Odoo) WithTLSConfig(config *tls.Config) *Odoo {
+	o.TLSConfig = config
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithRootCAs(pool *x509.CertPool) *Odoo {
+	o.RootCAs = pool
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithClientCertificate(cert tls.Certificate) *Odoo {
+	o.Certificates = append(o.Certificates, cert)
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithMinTLSVersion(version uint16) *Odoo {
+	o.MinTLSVersion = version
+	o.reconfigureClient()
+	return o
+}
+
+// WithInsecureSkipVerify disables server certificate verification, only
+// use it against development servers
+func (o *Odoo) WithInsecureSkipVerify(insecure bool) *Odoo {
+	o.InsecureSkipVerify = insecure
+	o.reconfigureClient()
+	return o
+}
+
+func (o *Odoo) WithCredentials(provider CredentialProvider) *Odoo {
+	o.CredentialProvider = provider
+	return o
+}
+
+// WithAPIKey authenticates username with an Odoo 14+ user API key instead of
+// a password
+func (o *Odoo) WithAPIKey(username, key string) *Odoo {
+	return o.WithCredentials(StaticCredentials{Username: username, Secret: key, APIKey: true})
+}
+
+func (o *Odoo) WithRetryPolicy(policy RetryPolicy) *Odoo {
+	o.RetryPolicy = policy
+	return o
+}
+
+func (o *Odoo) WithAuthMode(mode AuthMode) *Odoo {
+	o.Auth = mode
+	return o
+}
+
+func (o *Odoo) WithContext(key string, value any) *Odoo {
+	if o.Context == nil {
+		o.Context = map[string]any{}
+	}
+	o.Context[key] = value
+	return o
+}
+
+func (o *Odoo) WithLang(lang string) *Odoo {
+	return o.WithContext("lang", lang)
+}
+
+func (o *Odoo) WithTimezone(tz string) *Odoo {
+	return o.WithContext("tz", tz)
+}
+
+// WithCompanies sets the allowed companies, the first one is the current
+// company
+func (o *Odoo) WithCompanies(companyIDs ...int) *Odoo {
+	return o.WithContext("allowed_company_ids", companyIDs)
+}
+
+// Derive returns a copy of o sharing its login and pooled connection with
+// values merged over the default context, so one login can serve several
+// companies or languages concurrently
+func (o *Odoo) Derive(values map[string]any) *Odoo {
+	d := *o
+	d.st = o.conn()
+	d.Headers = make(map[string]string, len(o.Headers))
+	for k, v := range o.Headers {
+		d.Headers[k] = v
+	}
+	d.Context = make(map[string]any, len(o.Context)+len(values))
+	for k, v := range o.Context {
+		d.Context[k] = v
+	}
+	for k, v := range values {
+		d.Context[k] = v
+	}
+	return &d
+}
+
 func NewOdoo() *Odoo {
 	return &Odoo{}
 }
@@ -86,12 +268,15 @@ var (
 	ErrSchema  = errors.New("invalid schema: http or https")
 	ErrPort    = errors.New("invalid port: 1-65535")
 	ErrHostLen = errors.New("invalid hostname length: 1-2048")
+	// ErrNoResponse error on an empty JSON-RPC response
+	ErrNoResponse = errors.New("no response returned")
 )
 
 func (o *Odoo) Init() (err error) {
 	if err = o.genURL(); err != nil {
 		return fmt.Errorf("init error: %w", err)
 	}
+	o.resetConn()
 	return nil
 }
 
@@ -116,12 +301,17 @@ func (o *Odoo) genURL() (err error) {
 
 // Call sends a request
 func (o *Odoo) Call(service string, method string, args ...any) (res any, err error) {
+	return o.CallCtx(context.Background(), service, method, args...)
+}
+
+// CallCtx sends a request bound to ctx
+func (o *Odoo) CallCtx(ctx context.Context, service string, method string, args ...any) (res any, err error) {
 	params := map[string]any{
 		"service": service,
 		"method":  method,
 		"args":    args,
 	}
-	res, err = o.JSONRPC(params)
+	res, err = o.JSONRPCCtx(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +320,18 @@ func (o *Odoo) Call(service string, method string, args ...any) (res any, err er
 
 // JSONRPC json request
 func (o *Odoo) JSONRPC(params map[string]any) (res any, err error) {
+	return o.JSONRPCCtx(context.Background(), params)
+}
+
+// JSONRPCCtx json request bound to ctx, cancellation and deadline errors
+// wrap context.Canceled and context.DeadlineExceeded respectively
+func (o *Odoo) JSONRPCCtx(ctx context.Context, params map[string]any) (res any, err error) {
+	return o.postCtx(ctx, o.URL, params)
+}
+
+// postCtx sends a JSON-RPC call to endpoint, the session cookies are sent
+// and stored in the cookie jar of the connection
+func (o *Odoo) postCtx(ctx context.Context, endpoint string, params map[string]any) (res any, err error) {
 	message := map[string]any{
 		"jsonrpc": "2.0",
 		"method":  "call",
@@ -142,67 +344,248 @@ func (o *Odoo) JSONRPC(params map[string]any) (res any, err error) {
 		return nil, fmt.Errorf("json marshall error: %w", err)
 	}
 
-	// TODO: refactor timeout
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(bytesRepresentation))
+	if err != nil {
+		return nil, fmt.Errorf("http request error: %w", err)
+	}
+	for k, v := range o.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	st := o.conn()
+	for _, cookie := range st.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
 	}
 
-	// TODO: refactor insecure skip verify
-	if o.Schema == "https" && (o.Hostname == "localhost" || strings.HasSuffix(o.Hostname, ".local")) {
-		transCfg := &http.Transport{}
-		transCfg.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		client.Transport = transCfg
+	release, err := st.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	resp, err := client.Post(o.URL, "application/json", bytes.NewBuffer(bytesRepresentation))
+	resp, err := st.httpClient().Do(req)
 	if err != nil {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("http post error: %w", err)
 	}
-
-	var result map[string]any
-	if resp != nil {
-		json.NewDecoder(resp.Body).Decode(&result)
-	} else {
-		return nil, fmt.Errorf("no response returned")
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		st.jar.SetCookies(req.URL, cookies)
 	}
 
-	if _, ok := result["error"]; ok {
-		resultError := ""
-		if errorMessage, ok := result["error"].(map[string]any)["message"].(string); ok {
-			resultError += errorMessage
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return nil, ctxErr
 		}
-		if dataMessage, ok := result["error"].(map[string]any)["data"].(map[string]any)["message"].(string); ok {
-			resultError += ": " + dataMessage
+		return nil, fmt.Errorf("http read error: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		te := newTransportError(resp, body, nil)
+		if resp.StatusCode == http.StatusTooManyRequests && te.RetryAfter > 0 {
+			st.limiter.pause(te.RetryAfter)
 		}
-		return nil, fmt.Errorf(resultError)
+		return nil, te
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, newTransportError(resp, body, fmt.Errorf("json decode error: %w", err))
+	}
+	if result == nil {
+		return nil, newTransportError(resp, body, ErrNoResponse)
+	}
+
+	if e, ok := result["error"]; ok {
+		return nil, newOdooError(e)
 	}
 
 	res = result["result"]
 	return res, nil
 }
 
+// contextError wraps the error of a finished ctx so callers can tell
+// cancellation and deadline apart with errors.Is
+func contextError(ctx context.Context) error {
+	switch err := ctx.Err(); err {
+	case nil:
+		return nil
+	case context.Canceled:
+		return fmt.Errorf("request canceled: %w", err)
+	case context.DeadlineExceeded:
+		return fmt.Errorf("request deadline exceeded: %w", err)
+	default:
+		return fmt.Errorf("request aborted: %w", err)
+	}
+}
+
 // Login connects to server
 func (o *Odoo) Login() (err error) {
+	return o.LoginCtx(context.Background())
+}
+
+// LoginCtx connects to server, ErrLogin is returned when the server rejects
+// the credentials
+func (o *Odoo) LoginCtx(ctx context.Context) (err error) {
 	if o.URL == "" {
 		err = o.Init()
 		if err != nil {
 			return err
 		}
 	}
-	v, err := o.Call("common", "login", o.Database, o.Username, o.Password)
+	st := o.conn()
+	st.authMu.Lock()
+	defer st.authMu.Unlock()
+	return o.loginCtx(ctx)
+}
+
+// loginCtx authenticates with authMu held
+func (o *Odoo) loginCtx(ctx context.Context) (err error) {
+	if o.Auth == AuthSession {
+		return o.sessionLoginCtx(ctx)
+	}
+	c, err := o.credentials(ctx)
 	if err != nil {
 		return fmt.Errorf("login error: %w", err)
 	}
-	switch v := v.(type) {
-	case float64:
-		o.UID = int(v)
+	v, err := o.CallCtx(ctx, "common", "login", o.Database, c.Username, c.Secret)
+	if err != nil {
+		return fmt.Errorf("login error: %w", redactError(err, c.Secret))
+	}
+	uid, ok := v.(float64)
+	if !ok || uid <= 0 {
+		return fmt.Errorf("login error: %w", ErrLogin)
 	}
+	o.UID = int(uid)
 	return nil
 }
 
+// uid returns the logged in user id, logging in first when needed
+func (o *Odoo) uid(ctx context.Context) (int, error) {
+	st := o.conn()
+	st.authMu.Lock()
+	defer st.authMu.Unlock()
+	if o.UID == 0 {
+		if o.URL == "" {
+			if err := o.genURL(); err != nil {
+				return 0, fmt.Errorf("init error: %w", err)
+			}
+		}
+		if err := o.loginCtx(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return o.UID, nil
+}
+
+// relogin logs in again after an authentication failure of a call made as
+// uid, unless another call already did
+func (o *Odoo) relogin(ctx context.Context, uid int) error {
+	st := o.conn()
+	st.authMu.Lock()
+	defer st.authMu.Unlock()
+	if o.UID != uid && o.UID != 0 {
+		return nil
+	}
+	return o.loginCtx(ctx)
+}
+
+// ExecuteKw calls method on model through object.execute_kw
+func (o *Odoo) ExecuteKw(model string, method string, args []any, kwargs map[string]any) (res any, err error) {
+	return o.ExecuteKwCtx(context.Background(), model, method, args, kwargs)
+}
+
+// ExecuteKwCtx calls method on model through object.execute_kw bound to ctx.
+// The connection logs in on first use, and logs in again and retries once
+// when the session expired or the server rejects the user. Transient failures
+// are retried as the RetryPolicy allows.
+func (o *Odoo) ExecuteKwCtx(ctx context.Context, model string, method string, args []any, kwargs map[string]any) (res any, err error) {
+	if args == nil {
+		args = []any{}
+	}
+	args = normalize(args).([]any)
+	if len(o.Context) > 0 {
+		kwargs = mergeKwargs(map[string]any{"context": o.Context}, kwargs)
+	}
+	if kwargs == nil {
+		kwargs = map[string]any{}
+	}
+	kwargs = normalize(kwargs).(map[string]any)
+
+	return o.retryCtx(ctx, model, method, func() (any, error) {
+		return o.authExecuteKwCtx(ctx, model, method, args, kwargs)
+	})
+}
+
+// authExecuteKwCtx sends a model call, logging in again on auth failures
+func (o *Odoo) authExecuteKwCtx(ctx context.Context, model string, method string, args []any, kwargs map[string]any) (res any, err error) {
+	uid, err := o.uid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err = o.executeKwCtx(ctx, uid, model, method, args, kwargs)
+	if !IsAuthError(err) {
+		return res, err
+	}
+	if lerr := o.relogin(ctx, uid); lerr != nil {
+		return nil, fmt.Errorf("%w (after %v)", lerr, err)
+	}
+	uid, err = o.uid(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return o.executeKwCtx(ctx, uid, model, method, args, kwargs)
+}
+
+// executeKwCtx sends a single model call as uid
+func (o *Odoo) executeKwCtx(ctx context.Context, uid int, model string, method string, args []any, kwargs map[string]any) (res any, err error) {
+	if o.Auth == AuthSession {
+		return o.callKwCtx(ctx, model, method, args, kwargs)
+	}
+	c, err := o.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err = o.CallCtx(ctx, "object", "execute_kw", o.Database, uid, c.Secret, model, method, args, kwargs)
+	return res, redactError(err, c.Secret)
+}
+
+// mergeKwargs merges kwargs from left to right, the context maps are merged
+// key by key so a later context only overrides the keys it sets
+func mergeKwargs(kwargs ...map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, kw := range kwargs {
+		for k, v := range kw {
+			if c, ok := v.(map[string]any); ok && k == "context" {
+				merged := map[string]any{}
+				if prev, ok := out[k].(map[string]any); ok {
+					for ck, cv := range prev {
+						merged[ck] = cv
+					}
+				}
+				for ck, cv := range c {
+					merged[ck] = cv
+				}
+				out[k] = merged
+				continue
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
 // Create record
-func (o *Odoo) Create(model string, record map[string]any) (row int, res bool, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "create", record)
+func (o *Odoo) Create(model string, record map[string]any, kwargs ...map[string]any) (row int, res bool, err error) {
+	return o.CreateCtx(context.Background(), model, record, kwargs...)
+}
+
+// CreateCtx record
+func (o *Odoo) CreateCtx(ctx context.Context, model string, record map[string]any, kwargs ...map[string]any) (row int, res bool, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "create", []any{record}, mergeKwargs(kwargs...))
 	if err != nil {
 		return -1, false, err
 	}
@@ -216,8 +599,13 @@ func (o *Odoo) Create(model string, record map[string]any) (row int, res bool, e
 }
 
 // Load record
-func (o *Odoo) Load(model string, header []string, records []any) (row int, res bool, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "load", header, records)
+func (o *Odoo) Load(model string, header []string, records []any, kwargs ...map[string]any) (row int, res bool, err error) {
+	return o.LoadCtx(context.Background(), model, header, records, kwargs...)
+}
+
+// LoadCtx record
+func (o *Odoo) LoadCtx(ctx context.Context, model string, header []string, records []any, kwargs ...map[string]any) (row int, res bool, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "load", []any{header, records}, mergeKwargs(kwargs...))
 	if err != nil {
 		return -1, false, err
 	}
@@ -231,73 +619,131 @@ func (o *Odoo) Load(model string, header []string, records []any) (row int, res
 }
 
 // SearchRead records
-func (o *Odoo) SearchRead(model string, filter []any, offset int, limit int, fields []string) (recs []map[string]any, err error) {
-	vv, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "search_read", filter, fields, offset, limit)
+func (o *Odoo) SearchRead(model string, filter []any, offset int, limit int, fields []string, kwargs ...map[string]any) (recs []map[string]any, err error) {
+	return o.SearchReadCtx(context.Background(), model, filter, offset, limit, fields, kwargs...)
+}
+
+// SearchReadCtx records
+func (o *Odoo) SearchReadCtx(ctx context.Context, model string, filter []any, offset int, limit int, fields []string, kwargs ...map[string]any) (recs []map[string]any, err error) {
+	kw := map[string]any{}
+	if fields != nil {
+		kw["fields"] = fields
+	}
+	if offset > 0 {
+		kw["offset"] = offset
+	}
+	if limit > 0 {
+		kw["limit"] = limit
+	}
+	vv, err := o.ExecuteKwCtx(ctx, model, "search_read", []any{prefixDomain(filter)}, mergeKwargs(append([]map[string]any{kw}, kwargs...)...))
 	if err != nil {
 		return recs, err
 	}
 	switch vv := vv.(type) {
 	case []any:
 		for _, v := range vv {
-			recs = append(recs, v.(map[string]any))
+			if v, ok := v.(map[string]any); ok {
+				recs = append(recs, v)
+			}
 		}
 	}
 	return recs, nil
 }
 
+// SearchReadWithOptions records ordered and paged by opts
+func (o *Odoo) SearchReadWithOptions(model string, filter []any, fields []string, opts SearchOptions) (recs []map[string]any, err error) {
+	return o.SearchReadWithOptionsCtx(context.Background(), model, filter, fields, opts)
+}
+
+// SearchReadWithOptionsCtx records ordered and paged by opts
+func (o *Odoo) SearchReadWithOptionsCtx(ctx context.Context, model string, filter []any, fields []string, opts SearchOptions) (recs []map[string]any, err error) {
+	kw, err := opts.kwargs()
+	if err != nil {
+		return recs, err
+	}
+	return o.SearchReadCtx(ctx, model, filter, 0, 0, fields, kw)
+}
+
 // Search record
-func (o *Odoo) Search(model string, filter []any) (rows []int, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "search", filter)
+func (o *Odoo) Search(model string, filter []any, kwargs ...map[string]any) (rows []int, err error) {
+	return o.SearchCtx(context.Background(), model, filter, kwargs...)
+}
+
+// SearchCtx record
+func (o *Odoo) SearchCtx(ctx context.Context, model string, filter []any, kwargs ...map[string]any) (rows []int, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "search", []any{prefixDomain(filter)}, mergeKwargs(kwargs...))
 	if err != nil {
 		return rows, err
 	}
-	switch v := v.(type) {
-	case []any:
-		for _, v := range v {
-			rows = append(rows, int(v.(float64)))
-		}
+	return toIDs(v), nil
+}
+
+// SearchWithOptions record ordered and paged by opts
+func (o *Odoo) SearchWithOptions(model string, filter []any, opts SearchOptions) (rows []int, err error) {
+	return o.SearchWithOptionsCtx(context.Background(), model, filter, opts)
+}
+
+// SearchWithOptionsCtx record ordered and paged by opts
+func (o *Odoo) SearchWithOptionsCtx(ctx context.Context, model string, filter []any, opts SearchOptions) (rows []int, err error) {
+	kw, err := opts.kwargs()
+	if err != nil {
+		return rows, err
 	}
-	return rows, nil
+	return o.SearchCtx(ctx, model, filter, kw)
 }
 
 // GetID record
-func (o *Odoo) GetID(model string, filter []any) (out int, err error) {
+func (o *Odoo) GetID(model string, filter []any, kwargs ...map[string]any) (out int, err error) {
+	return o.GetIDCtx(context.Background(), model, filter, kwargs...)
+}
+
+// GetIDCtx record
+func (o *Odoo) GetIDCtx(ctx context.Context, model string, filter []any, kwargs ...map[string]any) (out int, err error) {
 	out = -1
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "search", filter)
+	rr, err := o.SearchCtx(ctx, model, filter, kwargs...)
 	if err != nil {
 		return out, err
 	}
-	switch v := v.(type) {
-	case []any:
-		rr := []int{}
-		for _, v := range v {
-			rr = append(rr, int(v.(float64)))
-		}
-		if len(rr) > 0 {
-			out = rr[0]
-		}
+	if len(rr) > 0 {
+		out = rr[0]
 	}
 	return out, nil
 }
 
 // Read record
-func (o *Odoo) Read(model string, ids []int, fields []string) (recs []map[string]any, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "read", ids, fields)
+func (o *Odoo) Read(model string, ids []int, fields []string, kwargs ...map[string]any) (recs []map[string]any, err error) {
+	return o.ReadCtx(context.Background(), model, ids, fields, kwargs...)
+}
+
+// ReadCtx record
+func (o *Odoo) ReadCtx(ctx context.Context, model string, ids []int, fields []string, kwargs ...map[string]any) (recs []map[string]any, err error) {
+	kw := map[string]any{}
+	if fields != nil {
+		kw["fields"] = fields
+	}
+	v, err := o.ExecuteKwCtx(ctx, model, "read", []any{ids}, mergeKwargs(append([]map[string]any{kw}, kwargs...)...))
 	if err != nil {
 		return recs, err
 	}
 	switch v := v.(type) {
 	case []any:
 		for _, v := range v {
-			recs = append(recs, v.(map[string]any))
+			if v, ok := v.(map[string]any); ok {
+				recs = append(recs, v)
+			}
 		}
 	}
 	return recs, nil
 }
 
 // Update record
-func (o *Odoo) Update(model string, recordID int, record map[string]any) (row int, res bool, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "write", recordID, record)
+func (o *Odoo) Update(model string, recordID int, record map[string]any, kwargs ...map[string]any) (row int, res bool, err error) {
+	return o.UpdateCtx(context.Background(), model, recordID, record, kwargs...)
+}
+
+// UpdateCtx record
+func (o *Odoo) UpdateCtx(ctx context.Context, model string, recordID int, record map[string]any, kwargs ...map[string]any) (row int, res bool, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "write", []any{[]int{recordID}, record}, mergeKwargs(kwargs...))
 	if err != nil {
 		return recordID, false, err
 	}
@@ -311,8 +757,13 @@ func (o *Odoo) Update(model string, recordID int, record map[string]any) (row in
 }
 
 // Unlink record
-func (o *Odoo) Unlink(model string, recordIDs []int) (res bool, err error) {
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "unlink", recordIDs)
+func (o *Odoo) Unlink(model string, recordIDs []int, kwargs ...map[string]any) (res bool, err error) {
+	return o.UnlinkCtx(context.Background(), model, recordIDs, kwargs...)
+}
+
+// UnlinkCtx record
+func (o *Odoo) UnlinkCtx(ctx context.Context, model string, recordIDs []int, kwargs ...map[string]any) (res bool, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "unlink", []any{recordIDs}, mergeKwargs(kwargs...))
 	if err != nil {
 		return res, err
 	}
@@ -326,11 +777,13 @@ func (o *Odoo) Unlink(model string, recordIDs []int) (res bool, err error) {
 }
 
 // Count record
-func (o *Odoo) Count(model string, filter []any) (count int, err error) {
-	if len(filter) == 0 {
-		filter = []any{[]any{"id", "!=", "-1"}}
-	}
-	v, err := o.Call("object", "execute", o.Database, o.UID, o.Password, model, "search_count", filter)
+func (o *Odoo) Count(model string, filter []any, kwargs ...map[string]any) (count int, err error) {
+	return o.CountCtx(context.Background(), model, filter, kwargs...)
+}
+
+// CountCtx record
+func (o *Odoo) CountCtx(ctx context.Context, model string, filter []any, kwargs ...map[string]any) (count int, err error) {
+	v, err := o.ExecuteKwCtx(ctx, model, "search_count", []any{prefixDomain(filter)}, mergeKwargs(kwargs...))
 	if err != nil {
 		return count, err
 	}
@@ -342,3 +795,39 @@ func (o *Odoo) Count(model string, filter []any) (count int, err error) {
 	}
 	return count, nil
 }
+
+// prefixDomain returns filter in the prefix notation expected by the server, the
+// operator groups returned by SearchDomain are flattened and a nil filter
+// matches every record
+func prefixDomain(filter []any) []any {
+	out := []any{}
+	for _, term := range filter {
+		out = appendTerm(out, term)
+	}
+	return out
+}
+
+func appendTerm(out []any, term any) []any {
+	group, ok := term.([]any)
+	if !ok || len(group) == 0 || !isDomainOperator(group[0]) {
+		return append(out, term)
+	}
+	out = append(out, group[0])
+	for _, operand := range group[1:] {
+		out = appendTerm(out, operand)
+	}
+	return out
+}
+
+// toIDs converts a list of ids returned by the server
+func toIDs(v any) (ids []int) {
+	switch v := v.(type) {
+	case []any:
+		for _, v := range v {
+			if id, ok := v.(float64); ok {
+				ids = append(ids, int(id))
+			}
+		}
+	}
+	return ids
+}
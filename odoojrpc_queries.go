@@ -18,13 +18,21 @@
 package odoojrpc
 
 import (
+	"context"
 	"strings"
+
+	"github.com/ppreeper/odoojrpc/domain"
 )
 
 // Common Odoo Queries
 func (o *Odoo) ModelMap(model string, field string) (map[string]int, error) {
+	return o.ModelMapCtx(context.Background(), model, field)
+}
+
+// ModelMapCtx maps field values to record ids
+func (o *Odoo) ModelMapCtx(ctx context.Context, model string, field string) (map[string]int, error) {
 	ids := map[string]int{}
-	rr, err := o.SearchRead(strings.Replace(model, "_", ".", -1), []any{}, 0, 0, []string{field})
+	rr, err := o.SearchReadCtx(ctx, strings.Replace(model, "_", ".", -1), []any{}, 0, 0, []string{field})
 	if err != nil {
 		return ids, err
 	}
@@ -42,25 +50,50 @@ func (o *Odoo) ModelMap(model string, field string) (map[string]int, error) {
 
 // CompanyID record
 func (o *Odoo) CompanyID(companyName string) (int, error) {
-	return o.GetID("res.company", []any{[]any{"name", "=", companyName}})
+	return o.CompanyIDCtx(context.Background(), companyName)
+}
+
+// CompanyIDCtx record
+func (o *Odoo) CompanyIDCtx(ctx context.Context, companyName string) (int, error) {
+	return o.GetIDCtx(ctx, "res.company", domain.MustBuild(domain.Field("name").Eq(companyName)))
 }
 
 // PartnerID record
 func (o *Odoo) PartnerID(partnerName string) (int, error) {
-	return o.GetID("res.partner", []any{[]any{"name", "=", partnerName}})
+	return o.PartnerIDCtx(context.Background(), partnerName)
+}
+
+// PartnerIDCtx record
+func (o *Odoo) PartnerIDCtx(ctx context.Context, partnerName string) (int, error) {
+	return o.GetIDCtx(ctx, "res.partner", domain.MustBuild(domain.Field("name").Eq(partnerName)))
 }
 
 // CountryID record
 func (o *Odoo) CountryID(countryName string) (int, error) {
-	return o.GetID("res.country", []any{[]any{"name", "=", countryName}})
+	return o.CountryIDCtx(context.Background(), countryName)
+}
+
+// CountryIDCtx record
+func (o *Odoo) CountryIDCtx(ctx context.Context, countryName string) (int, error) {
+	return o.GetIDCtx(ctx, "res.country", domain.MustBuild(domain.Field("name").Eq(countryName)))
 }
 
 // StateID record
 func (o *Odoo) StateID(countryID int, stateName string) (int, error) {
-	return o.GetID("res.country.state", []any{[]any{"name", "=", stateName}, []any{"country_id", "=", countryID}})
+	return o.StateIDCtx(context.Background(), countryID, stateName)
+}
+
+// StateIDCtx record
+func (o *Odoo) StateIDCtx(ctx context.Context, countryID int, stateName string) (int, error) {
+	return o.GetIDCtx(ctx, "res.country.state", domain.MustBuild(domain.Field("name").Eq(stateName).And(domain.Field("country_id").Eq(countryID))))
 }
 
 // FiscalPosition record
 func (o *Odoo) FiscalPosition(countryID int, fiscalName string) (int, error) {
-	return o.GetID("account.fiscal.position", []any{[]any{"country_id", "=", countryID}, []any{"name", "like", fiscalName}})
+	return o.FiscalPositionCtx(context.Background(), countryID, fiscalName)
+}
+
+// FiscalPositionCtx record
+func (o *Odoo) FiscalPositionCtx(ctx context.Context, countryID int, fiscalName string) (int, error) {
+	return o.GetIDCtx(ctx, "account.fiscal.position", domain.MustBuild(domain.Field("country_id").Eq(countryID).And(domain.Field("name").Like(fiscalName))))
 }
@@ -18,8 +18,15 @@
 package odoojrpc
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 var urlPatterns = []struct {
@@ -77,3 +84,124 @@ func TestInitError(t *testing.T) {
 		}
 	}
 }
+
+func TestCallCtx(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	o := &Odoo{URL: srv.URL + "/jsonrpc"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := o.CallCtx(ctx, "common", "version"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := o.CallCtx(ctx, "common", "version"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// newRPCServer returns a server answering every JSON-RPC call with the
+// result of handler
+func newRPCServer(t *testing.T, handler func(params map[string]any) any) (*Odoo, func()) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			ID     any            `json:"id"`
+			Params map[string]any `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decode request error: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      message.ID,
+			"result":  handler(message.Params),
+		})
+	}))
+	return &Odoo{URL: srv.URL + "/jsonrpc", Database: "odoo", UID: 2, Password: "secret"}, srv.Close
+}
+
+func TestExecuteKw(t *testing.T) {
+	var got map[string]any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		got = params
+		return []any{map[string]any{"id": 1.0, "name": "Admin"}}
+	})
+	defer done()
+
+	recs, err := o.SearchRead("res.partner", nil, 10, 5, []string{"name"},
+		map[string]any{"order": "name desc", "context": map[string]any{"lang": "fr_FR"}},
+		map[string]any{"context": map[string]any{"tz": "Europe/Paris"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0]["name"] != "Admin" {
+		t.Errorf("unexpected records %v", recs)
+	}
+	if got["service"] != "object" || got["method"] != "execute_kw" {
+		t.Errorf("expected object.execute_kw, got %v.%v", got["service"], got["method"])
+	}
+	args := got["args"].([]any)
+	if len(args) != 7 || args[3] != "res.partner" || args[4] != "search_read" {
+		t.Fatalf("unexpected args %v", args)
+	}
+	kwargs := args[6].(map[string]any)
+	expected := map[string]any{
+		"fields":  []any{"name"},
+		"offset":  10.0,
+		"limit":   5.0,
+		"order":   "name desc",
+		"context": map[string]any{"lang": "fr_FR", "tz": "Europe/Paris"},
+	}
+	if !reflect.DeepEqual(kwargs, expected) {
+		t.Errorf("expected kwargs %v, got %v", expected, kwargs)
+	}
+}
+
+func TestDefaultContext(t *testing.T) {
+	var got map[string]any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		args := params["args"].([]any)
+		got, _ = args[6].(map[string]any)["context"].(map[string]any)
+		return 1.0
+	})
+	defer done()
+	o.WithLang("en_US").WithTimezone("UTC").WithCompanies(1)
+
+	patterns := []struct {
+		odoo     *Odoo
+		kwargs   []map[string]any
+		expected map[string]any
+	}{
+		{o, nil, map[string]any{"lang": "en_US", "tz": "UTC", "allowed_company_ids": []any{1.0}}},
+		{o, []map[string]any{{"context": map[string]any{"lang": "de_DE"}}}, map[string]any{"lang": "de_DE", "tz": "UTC", "allowed_company_ids": []any{1.0}}},
+		{o.Derive(map[string]any{"allowed_company_ids": []int{2, 1}}), nil, map[string]any{"lang": "en_US", "tz": "UTC", "allowed_company_ids": []any{2.0, 1.0}}},
+	}
+	for i, pattern := range patterns {
+		if _, err := pattern.odoo.Count("res.partner", nil, pattern.kwargs...); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, pattern.expected) {
+			t.Errorf("\n[%d]: expected context %v, got %v", i, pattern.expected, got)
+		}
+	}
+	if !reflect.DeepEqual(o.Context["allowed_company_ids"], []int{1}) {
+		t.Errorf("derived copy changed the default context: %v", o.Context)
+	}
+}
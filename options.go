@@ -0,0 +1,86 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrOrder error on an invalid order clause
+var ErrOrder = errors.New("invalid order")
+
+// SearchOptions for SearchRead and Search
+type SearchOptions struct {
+	// Order is a comma separated list of fields with an optional direction,
+	// e.g. "date desc, id asc"
+	Order   string
+	Offset  int
+	Limit   int
+	Context map[string]any
+}
+
+var orderTerm = regexp.MustCompile(`^(?i)[a-z_][a-z0-9_.]*(:[a-z_]+)?(\s+(asc|desc))?(\s+nulls\s+(first|last))?$`)
+
+// orderTerms splits and validates an order clause
+func orderTerms(order string) (terms []string, err error) {
+	for _, term := range strings.Split(order, ",") {
+		term = strings.Join(strings.Fields(term), " ")
+		if !orderTerm.MatchString(term) {
+			return nil, fmt.Errorf("%w: %q", ErrOrder, term)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// kwargs returns the keyword arguments of the options, when paging through
+// an explicit order id is added as a tie breaker so pages do not overlap
+func (s SearchOptions) kwargs() (map[string]any, error) {
+	kw := map[string]any{}
+	if s.Order != "" {
+		terms, err := orderTerms(s.Order)
+		if err != nil {
+			return nil, err
+		}
+		if s.Offset > 0 || s.Limit > 0 {
+			hasID := false
+			for _, term := range terms {
+				if strings.EqualFold(strings.Fields(term)[0], "id") {
+					hasID = true
+				}
+			}
+			if !hasID {
+				terms = append(terms, "id")
+			}
+		}
+		kw["order"] = strings.Join(terms, ", ")
+	}
+	if s.Offset > 0 {
+		kw["offset"] = s.Offset
+	}
+	if s.Limit > 0 {
+		kw["limit"] = s.Limit
+	}
+	if s.Context != nil {
+		kw["context"] = s.Context
+	}
+	return kw, nil
+}
@@ -0,0 +1,73 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var orderPatterns = []struct {
+	opts     SearchOptions
+	expected map[string]any
+	err      error
+}{
+	{SearchOptions{}, map[string]any{}, nil},
+	{SearchOptions{Order: "name"}, map[string]any{"order": "name"}, nil},
+	{SearchOptions{Order: "date desc,  id ASC"}, map[string]any{"order": "date desc, id ASC"}, nil},
+	{SearchOptions{Order: "date desc", Limit: 80}, map[string]any{"order": "date desc, id", "limit": 80}, nil},
+	{SearchOptions{Order: "partner_id.name desc nulls last", Offset: 80, Limit: 80}, map[string]any{"order": "partner_id.name desc nulls last, id", "offset": 80, "limit": 80}, nil},
+	{SearchOptions{Context: map[string]any{"active_test": false}}, map[string]any{"context": map[string]any{"active_test": false}}, nil},
+	{SearchOptions{Order: "date desc,"}, nil, ErrOrder},
+	{SearchOptions{Order: "date; drop table"}, nil, ErrOrder},
+	{SearchOptions{Order: "date sideways"}, nil, ErrOrder},
+}
+
+func TestSearchOptions(t *testing.T) {
+	for i, pattern := range orderPatterns {
+		kw, err := pattern.opts.kwargs()
+		if !errors.Is(err, pattern.err) {
+			t.Errorf("\n[%d]: expected error %v, got %v", i, pattern.err, err)
+		}
+		if err == nil && !reflect.DeepEqual(kw, pattern.expected) {
+			t.Errorf("\n[%d]: expected %v, got %v", i, pattern.expected, kw)
+		}
+	}
+}
+
+func TestSearchWithOptions(t *testing.T) {
+	var got map[string]any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		got = params["args"].([]any)[6].(map[string]any)
+		return []any{3.0, 2.0}
+	})
+	defer done()
+
+	ids, err := o.SearchWithOptions("account.move.line", nil, SearchOptions{Order: "date desc", Offset: 2, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{3, 2}) {
+		t.Errorf("expected [3 2], got %v", ids)
+	}
+	expected := map[string]any{"order": "date desc, id", "offset": 2.0, "limit": 2.0}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected kwargs %v, got %v", expected, got)
+	}
+}
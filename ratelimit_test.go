@@ -0,0 +1,177 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	patterns := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"0", 0},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for i, pattern := range patterns {
+		if got := parseRetryAfter(pattern.header, now); got != pattern.expected {
+			t.Errorf("\n[%d]: expected %v, got %v", i, pattern.expected, got)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	l := newLimiter(10, 2, 0)
+	now := time.Now()
+	patterns := []struct {
+		at       time.Duration
+		expected time.Duration
+	}{
+		{0, 0},
+		{0, 0},
+		{0, 100 * time.Millisecond},
+		{50 * time.Millisecond, 50 * time.Millisecond},
+		{100 * time.Millisecond, 0},
+		{time.Second, 0},
+		{time.Second, 0},
+		{time.Second, 100 * time.Millisecond},
+	}
+	for i, pattern := range patterns {
+		got := l.reserve(now.Add(pattern.at))
+		if got < pattern.expected-time.Millisecond || got > pattern.expected+time.Millisecond {
+			t.Errorf("\n[%d]: expected wait %v, got %v", i, pattern.expected, got)
+		}
+	}
+
+	if got := newLimiter(0, 0, 0).reserve(now); got != 0 {
+		t.Errorf("expected no wait without rate, got %v", got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	o, done := newRPCServer(t, func(params map[string]any) any { return 1.0 })
+	defer done()
+	o.WithRateLimit(50, 1)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := o.Count("res.partner", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("expected 5 calls at 50/s to take at least 80ms, took %v", elapsed)
+	}
+
+	slow, done := newRPCServer(t, func(params map[string]any) any { return 1.0 })
+	defer done()
+	slow.WithRateLimit(0.1, 1)
+	if _, err := slow.Count("res.partner", nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := slow.CountCtx(ctx, "res.partner", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":1}`)
+	}))
+	defer srv.Close()
+
+	o := (&Odoo{URL: srv.URL + "/jsonrpc", UID: 2}).WithMaxInFlight(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := o.Count("res.partner", nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", peak)
+	}
+}
+
+func TestTooManyRequests(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":7}`)
+	}))
+	defer srv.Close()
+
+	o := (&Odoo{URL: srv.URL + "/jsonrpc", UID: 2}).WithRetryPolicy(&ExponentialBackoff{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+	})
+	start := time.Now()
+	id, _, err := o.Create("res.partner", map[string]any{"name": "Admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 || calls != 2 {
+		t.Errorf("expected id 7 after 2 calls, got %d after %d", id, calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected Retry-After to delay the retry, took %v", elapsed)
+	}
+
+	if d, ok := DefaultRetryPolicy.Backoff(FailedCall{
+		Attempt: 1,
+		Method:  "create",
+		Err:     &TransportError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute},
+	}); !ok || d != time.Minute {
+		t.Errorf("expected a 429 retry after 1m, got %v %v", d, ok)
+	}
+}
@@ -0,0 +1,186 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether a failed model call is sent again
+type RetryPolicy interface {
+	// Backoff returns the delay before the next attempt of the failed call,
+	// or false to give up
+	Backoff(call FailedCall) (time.Duration, bool)
+}
+
+// FailedCall describes a failed attempt of a model call
+type FailedCall struct {
+	Attempt    int // starts at 1
+	Model      string
+	Method     string
+	Idempotent bool // the method is idempotent or AllowRetry marks the call
+	Err        error
+}
+
+// ExponentialBackoff retries transient failures of idempotent methods with
+// an exponentially growing, jittered delay
+type ExponentialBackoff struct {
+	MaxAttempts    int           // total attempts including the first one
+	InitialBackoff time.Duration // delay after the first failure
+	MaxBackoff     time.Duration // upper bound of the delay
+	Multiplier     float64       // growth of the delay, 2 when zero
+	Jitter         float64       // fraction of the delay randomized, 0 to 1
+	// RetryNonIdempotent also retries methods such as create, which may
+	// then run twice when the failure happened after the server committed
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy retries 4 times from 200ms to 5s
+var DefaultRetryPolicy = &ExponentialBackoff{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+func (b *ExponentialBackoff) Backoff(call FailedCall) (time.Duration, bool) {
+	if call.Attempt >= b.MaxAttempts || !IsTransient(call.Err) {
+		return 0, false
+	}
+	var te *TransportError
+	errors.As(call.Err, &te)
+	// a 429 is rejected before running, it is safe to send again
+	tooMany := te != nil && te.StatusCode == http.StatusTooManyRequests
+	if !call.Idempotent && !b.RetryNonIdempotent && !tooMany {
+		return 0, false
+	}
+	multiplier := b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	delay := float64(b.InitialBackoff) * math.Pow(multiplier, float64(call.Attempt-1))
+	if b.MaxBackoff > 0 && delay > float64(b.MaxBackoff) {
+		delay = float64(b.MaxBackoff)
+	}
+	if b.Jitter > 0 {
+		delay -= delay * b.Jitter * rand.Float64()
+	}
+	if te != nil && float64(te.RetryAfter) > delay {
+		delay = float64(te.RetryAfter)
+	}
+	return time.Duration(delay), true
+}
+
+// idempotentMethods are the model methods safe to send twice, write is
+// not one of them as its x2many commands may create records
+var idempotentMethods = map[string]bool{
+	"search": true, "search_read": true, "search_count": true, "read": true, "read_group": true,
+	"fields_get": true, "name_search": true, "name_get": true, "default_get": true,
+	"check_access_rights": true, "web_read": true, "web_search_read": true,
+}
+
+// IsIdempotent reports whether sending method twice has the effect of
+// sending it once
+func IsIdempotent(method string) bool {
+	return idempotentMethods[method]
+}
+
+// concurrencyErrors are the PostgreSQL failures solved by running the
+// transaction again
+var concurrencyErrors = []string{
+	"could not serialize access",
+	"deadlock detected",
+	"could not obtain lock",
+	"SerializationFailure",
+	"TransactionRollbackError",
+	"LockNotAvailable",
+}
+
+// IsTransient reports whether err may not happen again: network errors,
+// 429, 502, 503 and 504 responses and Odoo concurrency errors
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var te *TransportError
+	if errors.As(err, &te) {
+		switch te.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var oe *OdooError
+	if errors.As(err, &oe) {
+		for _, s := range concurrencyErrors {
+			if strings.Contains(oe.Name, s) || strings.Contains(oe.DataMessage, s) || strings.Contains(oe.Debug, s) {
+				return true
+			}
+		}
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE)
+}
+
+// retryKey marks a context allowing retries of non-idempotent calls
+type retryKey struct{}
+
+// AllowRetry returns a context under which the calls are retried even when
+// not idempotent, e.g. a create known to be safe to send twice
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// retryCtx sends call again as the RetryPolicy allows
+func (o *Odoo) retryCtx(ctx context.Context, model string, method string, call func() (any, error)) (res any, err error) {
+	if o.RetryPolicy == nil {
+		return call()
+	}
+	allowed, _ := ctx.Value(retryKey{}).(bool)
+	failed := FailedCall{Model: model, Method: method, Idempotent: allowed || IsIdempotent(method)}
+	for failed.Attempt = 1; ; failed.Attempt++ {
+		res, err = call()
+		if err == nil {
+			return res, nil
+		}
+		failed.Err = err
+		delay, ok := o.RetryPolicy.Backoff(failed)
+		if !ok {
+			return nil, err
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var calls, failures int
+	var failure func(w http.ResponseWriter)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			failure(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": 1.0})
+	}))
+	defer srv.Close()
+
+	unavailable := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>503 Service Unavailable</html>"))
+	}
+	odooError := func(name, message string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{
+				"code": 200, "message": "Odoo Server Error", "data": map[string]any{"name": name, "message": message},
+			}})
+		}
+	}
+	serialization := odooError("psycopg2.errors.SerializationFailure", "could not serialize access due to concurrent update")
+	validation := odooError("odoo.exceptions.ValidationError", "invalid")
+
+	policy := &ExponentialBackoff{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Jitter: 0.5}
+	o := &Odoo{URL: srv.URL + "/jsonrpc", UID: 2}
+	o.WithRetryPolicy(policy)
+
+	count := func(ctx context.Context) error {
+		_, err := o.CountCtx(ctx, "res.partner", nil)
+		return err
+	}
+	create := func(ctx context.Context) error {
+		_, _, err := o.CreateCtx(ctx, "res.partner", map[string]any{"name": "Azure"})
+		return err
+	}
+	update := func(ctx context.Context) error {
+		_, _, err := o.UpdateCtx(ctx, "sale.order", 1, map[string]any{
+			"order_line": Commands{CommandCreate(map[string]any{"product_id": 1})},
+		})
+		return err
+	}
+	patterns := []struct {
+		call     func(context.Context) error
+		ctx      context.Context
+		failure  func(w http.ResponseWriter)
+		failures int
+		calls    int
+		ok       bool
+	}{
+		{count, context.Background(), unavailable, 2, 3, true},
+		{count, context.Background(), unavailable, 3, 3, false},
+		{count, context.Background(), serialization, 1, 2, true},
+		{count, context.Background(), validation, 1, 1, false},
+		{create, context.Background(), unavailable, 1, 1, false},
+		{create, AllowRetry(context.Background()), unavailable, 1, 2, true},
+		{update, context.Background(), unavailable, 1, 1, false},
+	}
+	for i, pattern := range patterns {
+		calls, failures, failure = 0, pattern.failures, pattern.failure
+		err := pattern.call(pattern.ctx)
+		if (err == nil) != pattern.ok || calls != pattern.calls {
+			t.Errorf("\n[%d]: expected ok %v in %d calls, got %v in %d calls", i, pattern.ok, pattern.calls, err, calls)
+		}
+	}
+
+	policy.RetryNonIdempotent = true
+	calls, failures, failure = 0, 1, unavailable
+	if err := create(context.Background()); err != nil || calls != 2 {
+		t.Errorf("expected a retried create, got %v in %d calls", err, calls)
+	}
+
+	policy.InitialBackoff, policy.MaxBackoff = time.Minute, time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls, failures = 0, 1
+	if err := count(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := &ExponentialBackoff{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	err := &TransportError{StatusCode: http.StatusBadGateway}
+	for i, expected := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		delay, ok := b.Backoff(FailedCall{Attempt: i + 1, Method: "read", Idempotent: true, Err: err})
+		if !ok || delay != expected*time.Millisecond {
+			t.Errorf("\n[%d]: expected %v, got %v %v", i, expected*time.Millisecond, delay, ok)
+		}
+	}
+	if _, ok := b.Backoff(FailedCall{Attempt: 10, Method: "read", Idempotent: true, Err: err}); ok {
+		t.Errorf("expected no retry after MaxAttempts")
+	}
+	if _, ok := b.Backoff(FailedCall{Attempt: 1, Method: "read", Idempotent: true, Err: &TransportError{StatusCode: http.StatusNotFound}}); ok {
+		t.Errorf("expected no retry of a 404")
+	}
+}
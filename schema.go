@@ -0,0 +1,171 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// FieldInfo describes a model field as returned by fields_get
+type FieldInfo struct {
+	Name             string            `odoo:"name"`
+	Type             string            `odoo:"type"`
+	String           string            `odoo:"string"`
+	Help             string            `odoo:"help"`
+	Required         bool              `odoo:"required"`
+	Readonly         bool              `odoo:"readonly"`
+	Store            bool              `odoo:"store"`
+	Searchable       bool              `odoo:"searchable"`
+	Sortable         bool              `odoo:"sortable"`
+	Relation         string            `odoo:"relation"`
+	RelationField    string            `odoo:"relation_field"`
+	Selection        []SelectionOption `odoo:"selection"`
+	Size             int               `odoo:"size"`
+	Translate        bool              `odoo:"translate"`
+	CompanyDependent bool              `odoo:"company_dependent"`
+}
+
+// fieldAttributes requested from fields_get
+var fieldAttributes = func() []string {
+	var names []string
+	for _, name := range StructFields(FieldInfo{}) {
+		if name != "name" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+// SelectionOption is a value of a selection field with its label
+type SelectionOption struct {
+	Value string
+	Label string
+}
+
+// UnmarshalJSON decodes a [value, label] pair
+func (s *SelectionOption) UnmarshalJSON(b []byte) error {
+	var pair []any
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("invalid selection option %s", b)
+	}
+	s.Value = fmt.Sprint(pair[0])
+	s.Label, _ = pair[1].(string)
+	return nil
+}
+
+// schema caches the fields of the models per language of their labels, it
+// is shared by the copies of an Odoo value
+type schema struct {
+	mu     sync.Mutex
+	models map[string]map[string]map[string]FieldInfo
+}
+
+func (s *schema) get(model, lang string) (map[string]FieldInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fields, ok := s.models[model][lang]
+	return fields, ok
+}
+
+func (s *schema) set(model, lang string, fields map[string]FieldInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.models == nil {
+		s.models = map[string]map[string]map[string]FieldInfo{}
+	}
+	if s.models[model] == nil {
+		s.models[model] = map[string]map[string]FieldInfo{}
+	}
+	s.models[model][lang] = fields
+}
+
+func (s *schema) invalidate(models ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(models) == 0 {
+		s.models = nil
+		return
+	}
+	for _, model := range models {
+		delete(s.models, model)
+	}
+}
+
+// Fields returns the fields of model keyed by name, the result of fields_get
+// is cached until InvalidateFields
+func (o *Odoo) Fields(model string) (map[string]FieldInfo, error) {
+	return o.FieldsCtx(context.Background(), model)
+}
+
+// FieldsCtx returns the fields of model keyed by name, the result of
+// fields_get is cached per context lang until InvalidateFields
+func (o *Odoo) FieldsCtx(ctx context.Context, model string) (map[string]FieldInfo, error) {
+	cache := &o.conn().schema
+	lang, _ := o.Context["lang"].(string)
+	fields, ok := cache.get(model, lang)
+	if !ok {
+		v, err := o.ExecuteKwCtx(ctx, model, "fields_get", nil, map[string]any{"attributes": fieldAttributes})
+		if err != nil {
+			return nil, err
+		}
+		fields, err = decodeFields(v)
+		if err != nil {
+			return nil, fmt.Errorf("fields_get %s: %w", model, err)
+		}
+		cache.set(model, lang, fields)
+	}
+	out := make(map[string]FieldInfo, len(fields))
+	for name, field := range fields {
+		out[name] = field
+	}
+	return out, nil
+}
+
+// InvalidateFields drops the cached fields of models, or of every model
+// when none is given
+func (o *Odoo) InvalidateFields(models ...string) {
+	o.conn().schema.invalidate(models...)
+}
+
+// decodeFields decodes a fields_get result
+func decodeFields(v any) (map[string]FieldInfo, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected fields_get result %T", ErrDecode, v)
+	}
+	fields := make(map[string]FieldInfo, len(m))
+	for name, attrs := range m {
+		attrs, ok := attrs.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: unexpected attributes of %s", ErrDecode, name)
+		}
+		var field FieldInfo
+		if err := Decode(attrs, &field); err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		field.Name = name
+		fields[name] = field
+	}
+	return fields, nil
+}
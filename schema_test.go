@@ -0,0 +1,76 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	calls := 0
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		calls++
+		label := "Customer"
+		kwargs := params["args"].([]any)[6].(map[string]any)
+		if ctx, _ := kwargs["context"].(map[string]any); ctx["lang"] == "fr_FR" {
+			label = "Client"
+		}
+		return map[string]any{
+			"partner_id": map[string]any{"type": "many2one", "string": label, "required": true, "relation": "res.partner", "store": true},
+			"state": map[string]any{"type": "selection", "string": "Status", "selection": []any{
+				[]any{"draft", "Quotation"}, []any{"sale", "Sales Order"},
+			}},
+			"priority": map[string]any{"type": "selection", "selection": []any{[]any{1.0, "High"}}, "help": false},
+		}
+	})
+	defer done()
+
+	fields, err := o.Fields("sale.order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]FieldInfo{
+		"partner_id": {Name: "partner_id", Type: "many2one", String: "Customer", Required: true, Relation: "res.partner", Store: true},
+		"state":      {Name: "state", Type: "selection", String: "Status", Selection: []SelectionOption{{"draft", "Quotation"}, {"sale", "Sales Order"}}},
+		"priority":   {Name: "priority", Type: "selection", Selection: []SelectionOption{{"1", "High"}}},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fields)
+	}
+
+	delete(fields, "state")
+	if fields, _ = o.Derive(nil).Fields("sale.order"); len(fields) != 3 || calls != 1 {
+		t.Errorf("expected 3 cached fields in 1 call, got %d in %d", len(fields), calls)
+	}
+	o.InvalidateFields("sale.order")
+	if _, err := o.Fields("sale.order"); err != nil || calls != 2 {
+		t.Errorf("expected a new fields_get call, got %d calls, %v", calls, err)
+	}
+
+	fr := o.Derive(map[string]any{"lang": "fr_FR"})
+	for i := 0; i < 2; i++ {
+		fields, err := fr.Fields("sale.order")
+		if err != nil || fields["partner_id"].String != "Client" || calls != 3 {
+			t.Errorf("\n[%d]: expected the fr_FR label in 3 calls, got %q in %d, %v", i, fields["partner_id"].String, calls, err)
+		}
+	}
+	if fields, _ := o.Fields("sale.order"); fields["partner_id"].String != "Customer" || calls != 3 {
+		t.Errorf("expected the cached default label, got %q in %d calls", fields["partner_id"].String, calls)
+	}
+}
@@ -18,165 +18,731 @@
 package odoojrpc
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
-	"regexp"
-	"sort"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-type StringNode struct {
-	Start int
-	End   int
-}
-
-var (
-	errSyntax     = errors.New("invalid syntax")
-	comparators   = `(=|!=|>|>=|<|<=|=?|=like|like|not like|ilike|not ilike|=ilike|in|not in|child_of|parent_of)`
-	baseArg       = `(\(\s*'\w+'\s*,\s*'` + comparators + `'\s*,\s*'(\w|\s)+'\s*\))`
-	sTerm         = `^` + baseArg + `$`
-	andorTerms    = `(('&'|'\|')\s*,\s*` + baseArg + `\s*,\s*` + baseArg + `)`
-	notTerms      = `('!'\s*,\s*` + baseArg + `)`
-	combinedTerms = `(,\s*(` + baseArg + `|` + andorTerms + `|` + notTerms + `))*`
-	mTerm         = `^(\[\s*` + baseArg + combinedTerms + `\s*\])$`
-	argCheck      = mTerm + "|" + sTerm
-)
+var errSyntax = errors.New("invalid syntax")
 
-var (
-	ac      = regexp.MustCompile(argCheck)
-	reSTerm = regexp.MustCompile(sTerm)
-	reMTerm = regexp.MustCompile(mTerm)
-)
+// SyntaxError reports the position of an invalid domain, it matches
+// errors.Is(err, errSyntax)
+type SyntaxError struct {
+	Offset int // byte offset in the domain
+	Msg    string
+}
 
-var (
-	reBase  = regexp.MustCompile(baseArg)
-	reAddOr = regexp.MustCompile(andorTerms)
-	reNot   = regexp.MustCompile(notTerms)
-)
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d: %s", errSyntax, e.Offset, e.Msg)
+}
 
-var (
-	reAndOrTerm = regexp.MustCompile(`^\s*('&'|'\|')`)
-	reNotTerm   = regexp.MustCompile(`^\s*('!')`)
-)
+func (e *SyntaxError) Unwrap() error {
+	return errSyntax
+}
 
-// term searches
-var (
-	fieldTerm      = regexp.MustCompile(`\(\s*'(\w)+'`)
-	comparatorTerm = regexp.MustCompile(`\s*,'` + comparators + `'\s*,`)
-	valueTerm      = regexp.MustCompile(`\s*'(\w|\s)+'\s*\)`)
-)
+// domainOperators accepted in a leaf
+var domainOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true, "=?": true,
+	"=like": true, "like": true, "not like": true, "ilike": true, "not ilike": true, "=ilike": true,
+	"in": true, "not in": true, "child_of": true, "parent_of": true, "any": true, "not any": true,
+}
 
+// SearchDomain parses a Python domain such as
+//
+//	['|', ('state', 'in', ['draft', 'sent']), '!', ('partner_id.country_id.code', '=', "US")]
+//
+// or a single leaf. The operands of '&', '|' and '!' are grouped with their
+// operator, strings, numbers, True, False, None, lists and tuples are
+// accepted as values.
 func SearchDomain(domain string) (filter []any, err error) {
-	if domain == "" {
+	p := &domainParser{src: domain}
+	if err := p.next(); err != nil {
+		return []any{}, err
+	}
+	if p.tok.kind == tokEOF {
 		return []any{}, nil
 	}
+	if p.tok.kind == tokPunct && p.tok.text == "(" {
+		leaf, err := p.parseLeaf()
+		if err != nil {
+			return []any{}, err
+		}
+		filter = []any{leaf}
+	} else {
+		if filter, err = p.parseDomain(); err != nil {
+			return []any{}, err
+		}
+	}
+	if p.tok.kind != tokEOF {
+		return []any{}, p.errorf("unexpected %s after domain", p.tok)
+	}
+	return filter, nil
+}
 
-	if !ac.MatchString(domain) {
-		return []any{}, errSyntax
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokString
+	tokNumber
+	tokName
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of domain"
 	}
+	return strconv.Quote(t.text)
+}
 
-	// single term
-	if reSTerm.MatchString(domain) {
-		return []any{patternSplit(domain)}, nil
+// domainParser is a recursive descent parser over the tokens of a domain
+type domainParser struct {
+	src string
+	off int
+	tok token
+}
+
+func (p *domainParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Offset: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next reads the next token
+func (p *domainParser) next() error {
+	for p.off < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.off:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.off += size
 	}
+	start := p.off
+	if p.off >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := p.src[p.off]
+	switch {
+	case strings.IndexByte("[](),", c) >= 0:
+		p.off++
+		p.tok = token{kind: tokPunct, text: string(c), pos: start}
+	case c == '\'' || c == '"':
+		s, err := p.scanString(c, false)
+		if err != nil {
+			return err
+		}
+		p.tok = token{kind: tokString, text: p.src[start:p.off], value: s, pos: start}
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.scanNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.off < len(p.src) && (p.src[p.off] == '_' || unicode.IsLetter(rune(p.src[p.off])) || unicode.IsDigit(rune(p.src[p.off]))) {
+			p.off++
+		}
+		name := p.src[start:p.off]
+		if stringPrefixes[strings.ToLower(name)] && p.off < len(p.src) && (p.src[p.off] == '\'' || p.src[p.off] == '"') {
+			s, err := p.scanString(p.src[p.off], strings.ContainsAny(name, "rR"))
+			if err != nil {
+				return err
+			}
+			p.tok = token{kind: tokString, text: p.src[start:p.off], value: s, pos: start}
+			return nil
+		}
+		p.tok = token{kind: tokName, text: name, pos: start}
+	default:
+		p.tok = token{pos: start}
+		return p.errorf("unexpected character %q", c)
+	}
+	return nil
+}
 
-	// multi term
-	if reMTerm.MatchString(domain) {
-		aa := []StringNode{}
-		nn := []StringNode{}
-		bb := []StringNode{}
+// stringPrefixes of the Python string and bytes literals, bytes are read
+// as strings
+var stringPrefixes = map[string]bool{"u": true, "r": true, "b": true, "br": true, "rb": true}
 
-		for _, v := range reAddOr.FindAllStringIndex(domain, -1) {
-			aa = append(aa, StringNode{Start: v[0], End: v[1]})
+// scanString reads a quoted string with Python escapes, a raw string keeps
+// its backslashes
+func (p *domainParser) scanString(quote byte, raw bool) (string, error) {
+	start := p.off
+	p.off++
+	var b strings.Builder
+	for p.off < len(p.src) {
+		c := p.src[p.off]
+		switch {
+		case c == quote:
+			p.off++
+			return b.String(), nil
+		case c == '\n':
+			return "", &SyntaxError{Offset: p.off, Msg: "newline in string"}
+		case c == '\\':
+			if p.off+1 >= len(p.src) {
+				return "", &SyntaxError{Offset: p.off, Msg: "unterminated escape"}
+			}
+			if raw {
+				b.WriteString(p.src[p.off : p.off+2])
+				p.off += 2
+				continue
+			}
+			if err := p.scanEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.off++
 		}
+	}
+	return "", &SyntaxError{Offset: start, Msg: "unterminated string"}
+}
 
-		for _, v := range reNot.FindAllStringIndex(domain, -1) {
-			nn = append(nn, StringNode{Start: v[0], End: v[1]})
+// scanEscape reads the escape sequence at the offset
+func (p *domainParser) scanEscape(b *strings.Builder) error {
+	escape := p.off
+	c := p.src[p.off+1]
+	p.off += 2
+	simple := map[byte]string{
+		'\\': "\\", '\'': "'", '"': "\"", 'n': "\n", 't': "\t", 'r': "\r",
+		'a': "\a", 'b': "\b", 'f': "\f", 'v': "\v", '\n': "",
+	}
+	if s, ok := simple[c]; ok {
+		b.WriteString(s)
+		return nil
+	}
+	if c >= '0' && c <= '7' {
+		// up to three octal digits
+		n := rune(c - '0')
+		for i := 0; i < 2 && p.off < len(p.src) && p.src[p.off] >= '0' && p.src[p.off] <= '7'; i++ {
+			n = n*8 + rune(p.src[p.off]-'0')
+			p.off++
 		}
+		b.WriteRune(n)
+		return nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 {
+		// Python keeps unknown escapes as is
+		b.WriteByte('\\')
+		b.WriteByte(c)
+		return nil
+	}
+	if p.off+digits > len(p.src) {
+		return &SyntaxError{Offset: escape, Msg: "truncated escape"}
+	}
+	n, err := strconv.ParseUint(p.src[p.off:p.off+digits], 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return &SyntaxError{Offset: escape, Msg: "invalid escape " + p.src[escape:p.off+digits]}
+	}
+	p.off += digits
+	b.WriteRune(rune(n))
+	return nil
+}
 
-		for _, v := range reBase.FindAllStringIndex(domain, -1) {
-			bb = append(bb, StringNode{Start: v[0], End: v[1]})
+// scanNumber reads an integer or a float written as a Python literal
+func (p *domainParser) scanNumber() error {
+	start := p.off
+	p.tok = token{kind: tokNumber, pos: start}
+	sign := ""
+	if c := p.src[p.off]; c == '-' || c == '+' {
+		// a unary sign may be followed by blanks, as in - 1
+		sign = string(c)
+		p.off++
+		for p.off < len(p.src) && (p.src[p.off] == ' ' || p.src[p.off] == '\t') {
+			p.off++
+		}
+	}
+	digits := p.off
+	if p.off+1 < len(p.src) && p.src[p.off] == '0' && strings.IndexByte("xXoObB", p.src[p.off+1]) >= 0 {
+		return p.scanPrefixedInt(start, sign+p.src[digits:p.off+2])
+	}
+	intPart, err := p.scanDigits(10)
+	if err != nil {
+		return err
+	}
+	isFloat := false
+	if p.off < len(p.src) && p.src[p.off] == '.' {
+		p.off++
+		isFloat = true
+		fracPart, err := p.scanDigits(10)
+		if err != nil {
+			return err
+		}
+		intPart = intPart || fracPart
+	}
+	if !intPart {
+		return p.errorf("invalid number %s", p.src[start:p.off])
+	}
+	if p.off < len(p.src) && (p.src[p.off] == 'e' || p.src[p.off] == 'E') {
+		p.off++
+		isFloat = true
+		if p.off < len(p.src) && (p.src[p.off] == '-' || p.src[p.off] == '+') {
+			p.off++
 		}
+		if ok, err := p.scanDigits(10); err != nil || !ok {
+			return p.errorf("invalid number %s", p.src[start:p.off])
+		}
+	}
+	if err := p.endNumber(start); err != nil {
+		return err
+	}
+	text := sign + p.src[digits:p.off]
+	p.tok.text = text
+	clean := strings.ReplaceAll(text, "_", "")
+	if !isFloat {
+		// Python rejects leading zeros in a non zero decimal integer
+		digits := strings.TrimLeft(clean, "+-")
+		if n, err := strconv.Atoi(clean); err == nil && !(len(digits) > 1 && digits[0] == '0' && n != 0) {
+			p.tok.value = n
+			return nil
+		}
+		return p.errorf("invalid number %s", text)
+	}
+	f, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return p.errorf("invalid number %s", text)
+	}
+	p.tok.value = f
+	return nil
+}
 
-		nl := buildNodeList(aa, nn, bb)
-		for _, n := range nl {
-			ss := stringSplit(domain, n.Start, n.End)
-			filter = append(filter, patternSplit(ss))
+// scanPrefixedInt reads a 0x, 0o or 0b integer after prefix, the sign and
+// base prefix, an underscore may follow the prefix
+func (p *domainParser) scanPrefixedInt(start int, prefix string) error {
+	base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[p.src[p.off+1]|0x20]
+	p.off += 2
+	digits := p.off
+	if p.off < len(p.src) && p.src[p.off] == '_' {
+		p.off++
+	}
+	ok, err := p.scanDigits(base)
+	if err == nil && ok {
+		err = p.endNumber(start)
+	}
+	if err != nil || !ok {
+		return p.errorf("invalid number %s", p.src[start:p.off])
+	}
+	text := prefix + p.src[digits:p.off]
+	p.tok.text = text
+	n, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return p.errorf("invalid number %s", text)
+	}
+	p.tok.value = int(n)
+	return nil
+}
+
+// endNumber rejects a number running into a name or another digit
+func (p *domainParser) endNumber(start int) error {
+	if p.off < len(p.src) {
+		if c := rune(p.src[p.off]); c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return p.errorf("invalid number %s", p.src[start:p.off+1])
 		}
-		return filter, nil
 	}
-	return filter, nil
+	return nil
+}
+
+// scanDigits reads digits of base, single underscores may only separate
+// two digits
+func (p *domainParser) scanDigits(base int) (bool, error) {
+	isDigit := func(c byte) bool {
+		d, err := strconv.ParseUint(string(c), base, 8)
+		return err == nil && int(d) < base
+	}
+	start := p.off
+	for p.off < len(p.src) {
+		switch c := p.src[p.off]; {
+		case isDigit(c):
+			p.off++
+		case c == '_' && p.off > start:
+			if p.off+1 >= len(p.src) || !isDigit(p.src[p.off+1]) {
+				return false, p.errorf("invalid number %s", p.src[p.tok.pos:p.off+1])
+			}
+			p.off++
+		default:
+			return p.off > start, nil
+		}
+	}
+	return p.off > start, nil
 }
 
-func buildNodeList(aa, nn, bb []StringNode) (nl []StringNode) {
-	nl = insideNodeList(nl, aa)
-	nl = insideNodeList(nl, nn)
-	nl = insideNodeList(nl, bb)
-	sort.Slice(nl, func(i int, j int) bool {
-		return nl[i].Start < nl[j].Start
-	})
-	return
+// expect consumes the punctuation text
+func (p *domainParser) expect(text string) error {
+	if p.tok.kind != tokPunct || p.tok.text != text {
+		return p.errorf("expected %q, got %s", text, p.tok)
+	}
+	return p.next()
 }
 
-func insideNodeList(aa, bb []StringNode) []StringNode {
-	for _, n := range bb {
-		inside := false
-		for _, a := range aa {
-			if n.Start >= a.Start && n.End <= a.End {
-				inside = true
+// parseDomain parses a list of operators and leaves
+func (p *domainParser) parseDomain() ([]any, error) {
+	open := p.tok
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var elems []token
+	var leaves []any
+	for !(p.tok.kind == tokPunct && p.tok.text == "]") {
+		switch {
+		case p.tok.kind == tokString && isDomainOperator(p.tok.value):
+			elems = append(elems, p.tok)
+			leaves = append(leaves, nil)
+			if err := p.next(); err != nil {
+				return nil, err
 			}
+		case p.tok.kind == tokPunct && (p.tok.text == "(" || p.tok.text == "["):
+			leaf, err := p.parseLeaf()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, token{})
+			leaves = append(leaves, leaf)
+		case p.tok.kind == tokEOF:
+			return nil, &SyntaxError{Offset: open.pos, Msg: "unclosed domain"}
+		default:
+			return nil, p.errorf("expected an operator or a leaf, got %s", p.tok)
+		}
+		if p.tok.kind == tokPunct && p.tok.text == "," {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		} else if p.tok.kind == tokEOF {
+			return nil, &SyntaxError{Offset: open.pos, Msg: "unclosed domain"}
+		} else if !(p.tok.kind == tokPunct && p.tok.text == "]") {
+			return nil, p.errorf("expected \",\" or \"]\", got %s", p.tok)
 		}
-		if !inside {
-			aa = append(aa, n)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	filter := []any{}
+	for i := 0; i < len(elems); {
+		term, n, err := groupTerm(elems[i:], leaves[i:])
+		if err != nil {
+			return nil, err
 		}
+		filter = append(filter, term)
+		i += n
 	}
-	return aa
+	return filter, nil
 }
 
-func stringSplit(term string, start, end int) string {
-	b := []byte(term)
-	bStr := b[start:end]
-	return string(bStr)
+// groupTerm groups the operator at the head of elems with its operands,
+// it returns the term and the number of elements used
+func groupTerm(elems []token, leaves []any) (term any, n int, err error) {
+	if leaves[0] != nil {
+		return leaves[0], 1, nil
+	}
+	op := elems[0].value.(string)
+	arity := 2
+	if op == "!" {
+		arity = 1
+	}
+	group := []any{op}
+	n = 1
+	for i := 0; i < arity; i++ {
+		if n >= len(elems) {
+			return nil, 0, &SyntaxError{Offset: elems[0].pos, Msg: fmt.Sprintf("missing operand of %q", op)}
+		}
+		operand, used, err := groupTerm(elems[n:], leaves[n:])
+		if err != nil {
+			return nil, 0, err
+		}
+		group = append(group, operand)
+		n += used
+	}
+	return group, n, nil
 }
 
-func patternSplit(statement string) []any {
-	if reAddOr.MatchString(statement) {
-		opCondition := reAndOrTerm.FindAllString(statement, -1)
-		op := termTrimQuote(opCondition[0])
-		terms := reBase.FindAllString(statement, -1)
-		t1 := termSplit(terms[0])
-		t2 := termSplit(terms[1])
-		return []any{op, []any{t1[0], t1[1], t1[2]}, []any{t2[0], t2[1], t2[2]}}
+func isDomainOperator(v any) bool {
+	return v == "&" || v == "|" || v == "!"
+}
+
+// parseLeaf parses a (field, operator, value) tuple or list
+func (p *domainParser) parseLeaf() ([]any, error) {
+	open := p.tok
+	group, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	values, ok := group.([]any)
+	if !ok {
+		return nil, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("expected a leaf, got %v", group)}
+	}
+	if len(values) != 3 {
+		return nil, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("leaf needs 3 items, got %d", len(values))}
 	}
+	switch field := values[0].(type) {
+	case string:
+		if field == "" {
+			return nil, &SyntaxError{Offset: open.pos, Msg: "empty field name"}
+		}
+	case int:
+		if field != 0 && field != 1 {
+			return nil, &SyntaxError{Offset: open.pos, Msg: "invalid field " + strconv.Itoa(field)}
+		}
+	default:
+		return nil, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("invalid field %v", values[0])}
+	}
+	op, ok := values[1].(string)
+	if !ok || !domainOperators[strings.ToLower(op)] {
+		return nil, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("invalid operator %v", values[1])}
+	}
+	return values, nil
+}
 
-	if reNot.MatchString(statement) {
-		opCondition := reNotTerm.FindAllString(statement, -1)
-		op := termTrimQuote(opCondition[0])
-		terms := reBase.FindAllString(statement, -1)
-		tt := termSplit(terms[0])
-		return []any{op, []any{tt[0], tt[1], tt[2]}}
+// parseGroup parses a list or a tuple, as in Python a parenthesized value
+// without a comma is the value itself
+func (p *domainParser) parseGroup() (any, error) {
+	paren := p.tok.text == "("
+	values, commas, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+	if paren && len(values) == 1 && commas == 0 {
+		return values[0], nil
+	}
+	return values, nil
+}
+
+// parseSequence parses a list or a tuple of values and counts the commas
+func (p *domainParser) parseSequence() (values []any, commas int, err error) {
+	open := p.tok
+	closing := map[string]string{"(": ")", "[": "]"}[open.text]
+	if err := p.next(); err != nil {
+		return nil, 0, err
+	}
+	values = []any{}
+	for !(p.tok.kind == tokPunct && p.tok.text == closing) {
+		if p.tok.kind == tokEOF {
+			return nil, 0, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("unclosed %q", open.text)}
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, 0, err
+		}
+		values = append(values, v)
+		if p.tok.kind == tokPunct && p.tok.text == "," {
+			commas++
+			if err := p.next(); err != nil {
+				return nil, 0, err
+			}
+		} else if p.tok.kind == tokEOF {
+			return nil, 0, &SyntaxError{Offset: open.pos, Msg: fmt.Sprintf("unclosed %q", open.text)}
+		} else if !(p.tok.kind == tokPunct && p.tok.text == closing) {
+			return nil, 0, p.errorf("expected \",\" or %q, got %s", closing, p.tok)
+		}
 	}
+	return values, commas, p.next()
+}
 
-	if reBase.MatchString(statement) {
-		terms := reBase.FindAllString(statement, -1)
-		tt := termSplit(terms[0])
-		return []any{tt[0], tt[1], tt[2]}
+// parseValue parses a literal
+func (p *domainParser) parseValue() (v any, err error) {
+	switch p.tok.kind {
+	case tokString:
+		s := p.tok.value.(string)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		// adjacent literals are concatenated
+		for p.tok.kind == tokString {
+			s += p.tok.value.(string)
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	case tokNumber:
+		v = p.tok.value
+	case tokName:
+		switch p.tok.text {
+		case "True":
+			v = true
+		case "False":
+			v = false
+		case "None":
+			v = nil
+		default:
+			return nil, p.errorf("unknown name %s", p.tok.text)
+		}
+	case tokPunct:
+		if p.tok.text == "(" || p.tok.text == "[" {
+			return p.parseGroup()
+		}
+		return nil, p.errorf("unexpected %s", p.tok)
+	default:
+		return nil, p.errorf("unexpected %s", p.tok)
 	}
-	return []any{}
+	return v, p.next()
 }
 
-func termSplit(term string) []string {
-	field := fieldTerm.FindAllString(term, -1)
-	fieldStr := termTrimQuote(strings.Trim(field[0], "("))
+// ErrDomain error on a domain that cannot be formatted
+var ErrDomain = errors.New("invalid domain")
 
-	comparator := comparatorTerm.FindAllString(term, -1)
-	comparatorStr := termTrimQuote(strings.Trim(comparator[0], ","))
+// FormatDomain returns the canonical Python text of filter, in prefix
+// notation or grouped as returned by SearchDomain, so that SearchDomain
+// parses it back. time.Time values are UTC datetimes, compare date fields
+// with a Date.
+func FormatDomain(filter []any) (string, error) {
+	flat := prefixDomain(normalize(filter).([]any))
+	operands := 0
+	for i := len(flat) - 1; i >= 0; i-- {
+		switch term := flat[i]; {
+		case term == "!":
+			if operands < 1 {
+				return "", fmt.Errorf("%w: missing operand of %q", ErrDomain, term)
+			}
+		case term == "&" || term == "|":
+			if operands < 2 {
+				return "", fmt.Errorf("%w: missing operand of %q", ErrDomain, term)
+			}
+			operands--
+		default:
+			operands++
+		}
+	}
 
-	value := valueTerm.FindAllString(term, -1)
-	valueStr := termTrimQuote(strings.Trim(value[0], ")"))
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, term := range flat {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if isDomainOperator(term) {
+			b.WriteString(quotePython(term.(string)))
+			continue
+		}
+		if err := formatLeaf(&b, term); err != nil {
+			return "", err
+		}
+	}
+	b.WriteByte(']')
+	return b.String(), nil
+}
 
-	return []string{fieldStr, comparatorStr, valueStr}
+// formatLeaf writes a (field, operator, value) tuple
+func formatLeaf(b *strings.Builder, term any) error {
+	rv := reflect.ValueOf(term)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 3 {
+		return fmt.Errorf("%w: expected an operator or a leaf, got %v", ErrDomain, term)
+	}
+	leaf := []any{rv.Index(0).Interface(), rv.Index(1).Interface(), rv.Index(2).Interface()}
+	if op, ok := leaf[1].(string); !ok || !domainOperators[strings.ToLower(op)] {
+		return fmt.Errorf("%w: invalid operator %v", ErrDomain, leaf[1])
+	}
+	b.WriteByte('(')
+	for i, v := range leaf {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := formatValue(b, v); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
 }
 
-func termTrimQuote(term string) string {
-	return strings.TrimSpace(strings.Trim(term, "'"))
+// formatValue writes v as a Python literal
+func formatValue(b *strings.Builder, v any) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("None")
+	case bool:
+		if v {
+			b.WriteString("True")
+		} else {
+			b.WriteString("False")
+		}
+	case string:
+		b.WriteString(quotePython(v))
+	case float32:
+		return formatValue(b, float64(v))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("%w: %v has no literal", ErrDomain, v)
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		b.WriteString(s)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprint(b, v)
+	case json.Number:
+		b.WriteString(string(v))
+	case json.Marshaler:
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDomain, err)
+		}
+		// numbers are kept as written, 2 stays an int and 2.0 a float
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var decoded any
+		if err := dec.Decode(&decoded); err != nil {
+			return fmt.Errorf("%w: %v", ErrDomain, err)
+		}
+		return formatValue(b, decoded)
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("%w: unsupported value %T", ErrDomain, v)
+		}
+		b.WriteByte('[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := formatValue(b, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	}
+	return nil
+}
+
+// quotePython returns s as a single quoted Python string
+func quotePython(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			switch {
+			case r < 0x20 || r == 0x7f:
+				fmt.Fprintf(&b, `\x%02x`, r)
+			case !unicode.IsPrint(r) && r <= 0xffff:
+				fmt.Fprintf(&b, `\u%04x`, r)
+			case !unicode.IsPrint(r):
+				fmt.Fprintf(&b, `\U%08x`, r)
+			default:
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
 }
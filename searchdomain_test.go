@@ -18,8 +18,14 @@
 package odoojrpc
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
+	"testing/quick"
+	"time"
 )
 
 var searchDomainPatterns = []struct {
@@ -57,8 +63,206 @@ func TestSearchDomain(t *testing.T) {
 		if !reflect.DeepEqual(pattern.args, args) {
 			t.Errorf("\n[%d]: expected reflect args: %v, got %v", i, pattern.args, args)
 		}
-		if err != pattern.err {
+		if !errors.Is(err, pattern.err) || (err == nil) != (pattern.err == nil) {
 			t.Errorf("\n[%d]: expected error: %v, got %v", i, pattern.err, err)
 		}
 	}
 }
+
+var searchDomainGrammarPatterns = []struct {
+	domain string
+	args   []any
+}{
+	{"[]", []any{}},
+	{`[("partner_id.country_id.code", "=", "US")]`, []any{[]any{"partner_id.country_id.code", "=", "US"}}},
+	{`[('state', 'in', ['draft', 'sent']), ('id', 'not in', (1, 2,))]`, []any{[]any{"state", "in", []any{"draft", "sent"}}, []any{"id", "not in", []any{1, 2}}}},
+	{`[('active', '=', False), ('parent_id', '!=', None), ('is_company', '=', True)]`, []any{[]any{"active", "=", false}, []any{"parent_id", "!=", nil}, []any{"is_company", "=", true}}},
+	{`[('amount', '>=', -1.5e2), ('qty', '<', 1_000)]`, []any{[]any{"amount", ">=", -150.0}, []any{"qty", "<", 1000}}},
+	{`[('flags', 'in', [0x10, 0XfF, 0o17, 0b1_01, -0x_1f, 00, 1., .5, 1_0.2_5e1_0])]`, []any{[]any{"flags", "in", []any{16, 255, 15, 5, -31, 0, 1.0, 0.5, 10.25e10}}}},
+	{`[('name', 'ilike', 'O\'Brien "\x41\u00e9"\n')]`, []any{[]any{"name", "ilike", "O'Brien \"A\u00e9\"\n"}}},
+	{`[['name', '=', 'a' "b"],]`, []any{[]any{"name", "=", "ab"}}},
+	{`[(1, '=', 1)]`, []any{[]any{1, "=", 1}}},
+	{`[('id', '=', (1)), ('id', 'in', (1,)), ('id', 'not in', ()), (('name', '=', ('x')))]`, []any{[]any{"id", "=", 1}, []any{"id", "in", []any{1}}, []any{"id", "not in", []any{}}, []any{"name", "=", "x"}}},
+	{`[('name', '=', '\101\0120\0'), ('name', '=', u'x' r'\n\'' b"y" Rb'\z')]`, []any{[]any{"name", "=", "A\n0\x00"}, []any{"name", "=", "x\\n\\'y\\z"}}},
+	{`[('qty', '>', - 1), ('qty', '<', +  0x10)]`, []any{[]any{"qty", ">", -1}, []any{"qty", "<", 16}}},
+	{"['|', '|', ('a', '=', 1), ('b', '=', 2), '&', ('c', '=', 3), '!', ('d', '=', 4)]", []any{[]any{"|", []any{"|", []any{"a", "=", 1}, []any{"b", "=", 2}}, []any{"&", []any{"c", "=", 3}, []any{"!", []any{"d", "=", 4}}}}}},
+}
+
+func TestSearchDomainGrammar(t *testing.T) {
+	for i, pattern := range searchDomainGrammarPatterns {
+		args, err := SearchDomain(pattern.domain)
+		if err != nil {
+			t.Errorf("\n[%d]: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(pattern.args, args) {
+			t.Errorf("\n[%d]: expected reflect args: %v, got %v", i, pattern.args, args)
+		}
+	}
+}
+
+var searchDomainErrorPatterns = []struct {
+	domain string
+	offset int
+}{
+	{"[('name', '=', 'x')", 0},
+	{"[('name', '=', 'x') ('a', '=', 1)]", 20},
+	{"[('name', '~', 'x')]", 1},
+	{"[('name', '=')]", 1},
+	{"[('name', '=', uid)]", 15},
+	{"[('name', '=', 'x)]", 15},
+	{"['&', ('name', '=', 'x')]", 1},
+	{"[('name', '=', 'x')] x", 21},
+	{"[('name', '=', @)]", 15},
+	{`[('name', '=', '\u00')]`, 16},
+	{"[('qty', '=', 1_)]", 14},
+	{"[('qty', '=', 1__0)]", 14},
+	{"[('qty', '=', 1_.5)]", 14},
+	{"[('qty', '=', 012)]", 14},
+	{"[('qty', '=', 0x)]", 14},
+	{"[('qty', '=', 0b12)]", 14},
+	{"[('qty', '=', 1e)]", 14},
+	{"[('qty', '=', -)]", 14},
+	{"[('qty', '=', 1abc)]", 14},
+	{"[(1)]", 1},
+	{"[('name', '=', x'y')]", 15},
+}
+
+func TestSearchDomainError(t *testing.T) {
+	for i, pattern := range searchDomainErrorPatterns {
+		_, err := SearchDomain(pattern.domain)
+		var se *SyntaxError
+		if !errors.As(err, &se) || !errors.Is(err, errSyntax) {
+			t.Errorf("\n[%d]: expected *SyntaxError, got %v", i, err)
+			continue
+		}
+		if se.Offset != pattern.offset {
+			t.Errorf("\n[%d]: expected offset %d, got %d (%v)", i, pattern.offset, se.Offset, err)
+		}
+	}
+}
+
+func TestSearchDomainPrefix(t *testing.T) {
+	var got []any
+	o, done := newRPCServer(t, func(params map[string]any) any {
+		got = params["args"].([]any)[5].([]any)[0].([]any)
+		return []any{}
+	})
+	defer done()
+
+	filter, err := SearchDomain("[('a', '=', 1), '|', ('b', '=', 2), '!', ('c', '=', 3)]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Search("res.partner", filter); err != nil {
+		t.Fatal(err)
+	}
+	expected := []any{[]any{"a", "=", 1.0}, "|", []any{"b", "=", 2.0}, "!", []any{"c", "=", 3.0}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+var formatDomainPatterns = []struct {
+	filter   []any
+	expected string
+	err      error
+}{
+	{nil, "[]", nil},
+	{[]any{[]any{"name", "=", "O'Brien\n"}}, `[('name', '=', 'O\'Brien\n')]`, nil},
+	{[]any{"|", []any{"a", "in", []int{1, 2}}, []any{"b", "=", 2.0}}, "['|', ('a', 'in', [1, 2]), ('b', '=', 2.0)]", nil},
+	{[]any{[]any{"!", []any{"active", "=", false}}, []any{"parent_id", "!=", nil}}, "['!', ('active', '=', False), ('parent_id', '!=', None)]", nil},
+	{[]any{[]any{1, "=", 1}, []any{"partner_id", "=", NewMany2One(3)}}, "[(1, '=', 1), ('partner_id', '=', 3)]", nil},
+	{[]any{[]any{"date", ">=", time.Date(2024, 1, 31, 13, 45, 0, 0, time.UTC)}}, "[('date', '>=', '2024-01-31 13:45:00')]", nil},
+	{[]any{[]any{"line_ids", "=", Commands{CommandSet(1, 2)}}, []any{"qty", "=", json.RawMessage("2.0")}}, "[('line_ids', '=', [[6, 0, [1, 2]]]), ('qty', '=', 2.0)]", nil},
+	{[]any{[]any{"qty", "in", json.RawMessage("[1, 1.5, 1e3, 12345678901234567890]")}}, "[('qty', 'in', [1, 1.5, 1e3, 12345678901234567890])]", nil},
+	{[]any{"&", []any{"a", "=", 1}}, "", ErrDomain},
+	{[]any{[]any{"a", "~", 1}}, "", ErrDomain},
+	{[]any{[]any{"a", "="}}, "", ErrDomain},
+	{[]any{[]any{"a", "=", math.Inf(1)}}, "", ErrDomain},
+	{[]any{[]any{"a", "=", map[string]any{}}}, "", ErrDomain},
+}
+
+func TestFormatDomain(t *testing.T) {
+	for i, pattern := range formatDomainPatterns {
+		got, err := FormatDomain(pattern.filter)
+		if !errors.Is(err, pattern.err) || (err == nil) != (pattern.err == nil) {
+			t.Errorf("\n[%d]: expected error %v, got %v", i, pattern.err, err)
+		}
+		if got != pattern.expected {
+			t.Errorf("\n[%d]: expected %s, got %s", i, pattern.expected, got)
+		}
+	}
+}
+
+// genDomain is a random domain grouped as returned by SearchDomain
+type genDomain []any
+
+func (genDomain) Generate(r *rand.Rand, size int) reflect.Value {
+	d := genDomain{}
+	for n := r.Intn(4); n >= 0; n-- {
+		d = append(d, genTerm(r, 3))
+	}
+	return reflect.ValueOf(d)
+}
+
+func genTerm(r *rand.Rand, depth int) any {
+	switch n := r.Intn(5); {
+	case depth > 0 && n == 0:
+		return []any{"!", genTerm(r, depth-1)}
+	case depth > 0 && n == 1:
+		return []any{[]string{"&", "|"}[r.Intn(2)], genTerm(r, depth-1), genTerm(r, depth-1)}
+	}
+	ops := []string{"=", "!=", "<", ">=", "ilike", "not in", "child_of", "=?"}
+	fields := []string{"name", "partner_id.country_id.code", "x_2"}
+	return []any{fields[r.Intn(len(fields))], ops[r.Intn(len(ops))], genValue(r, 2)}
+}
+
+func genValue(r *rand.Rand, depth int) any {
+	switch r.Intn(7) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return r.Intn(2000) - 1000
+	case 3:
+		return r.NormFloat64() * math.Pow(10, float64(r.Intn(40)-20))
+	case 4:
+		if depth > 0 {
+			l := []any{}
+			for n := r.Intn(4); n > 0; n-- {
+				l = append(l, genValue(r, depth-1))
+			}
+			return l
+		}
+	}
+	runes := []rune{'a', 'Z', ' ', '\'', '"', '\\', '\n', '\t', 0, 0x7f, 'é', '€', 0x2028, 0x1f600, 0xe000}
+	s := make([]rune, r.Intn(8))
+	for i := range s {
+		s[i] = runes[r.Intn(len(runes))]
+	}
+	return string(s)
+}
+
+func TestFormatDomainRoundTrip(t *testing.T) {
+	roundTrip := func(d genDomain) bool {
+		text, err := FormatDomain(d)
+		if err != nil {
+			t.Logf("format %v: %v", d, err)
+			return false
+		}
+		parsed, err := SearchDomain(text)
+		if err != nil {
+			t.Logf("parse %s: %v", text, err)
+			return false
+		}
+		if !reflect.DeepEqual(parsed, []any(d)) {
+			t.Logf("%s\nexpected %#v\ngot      %#v", text, []any(d), parsed)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
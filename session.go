@@ -0,0 +1,103 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AuthMode selects how model calls are authenticated
+type AuthMode int
+
+const (
+	// AuthPassword logs in with common.login and sends the password with
+	// every object.execute_kw call on /jsonrpc
+	AuthPassword AuthMode = iota
+	// AuthSession logs in on /web/session/authenticate and calls the
+	// /web/dataset/call_kw endpoints with the session_id cookie
+	AuthSession
+)
+
+// baseURL returns the server URL without the /jsonrpc path
+func (o *Odoo) baseURL() string {
+	return strings.TrimSuffix(o.URL, "/jsonrpc")
+}
+
+// sessionLoginCtx authenticates the session, the session_id cookie is kept
+// in the cookie jar of the connection
+func (o *Odoo) sessionLoginCtx(ctx context.Context) error {
+	c, err := o.credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("login error: %w", err)
+	}
+	if c.APIKey {
+		return fmt.Errorf("login error: %w", ErrAPIKeySession)
+	}
+	v, err := o.postCtx(ctx, o.baseURL()+"/web/session/authenticate", map[string]any{
+		"db":       o.Database,
+		"login":    c.Username,
+		"password": c.Secret,
+	})
+	if IsAccessDenied(err) {
+		return fmt.Errorf("login error: %w (%v)", ErrLogin, redactError(err, c.Secret))
+	}
+	if err != nil {
+		return fmt.Errorf("login error: %w", redactError(err, c.Secret))
+	}
+	info, _ := v.(map[string]any)
+	uid, ok := info["uid"].(float64)
+	if !ok {
+		return fmt.Errorf("login error: %w", ErrLogin)
+	}
+	o.UID = int(uid)
+	return nil
+}
+
+// callKwCtx calls method on model through /web/dataset/call_kw
+func (o *Odoo) callKwCtx(ctx context.Context, model string, method string, args []any, kwargs map[string]any) (any, error) {
+	endpoint := o.baseURL() + "/web/dataset/call_kw/" + url.PathEscape(model) + "/" + url.PathEscape(method)
+	return o.postCtx(ctx, endpoint, map[string]any{
+		"model":  model,
+		"method": method,
+		"args":   args,
+		"kwargs": kwargs,
+	})
+}
+
+// Logout destroys the session of an AuthSession connection
+func (o *Odoo) Logout() error {
+	return o.LogoutCtx(context.Background())
+}
+
+// LogoutCtx destroys the session of an AuthSession connection
+func (o *Odoo) LogoutCtx(ctx context.Context) error {
+	if o.Auth != AuthSession {
+		return nil
+	}
+	st := o.conn()
+	st.authMu.Lock()
+	defer st.authMu.Unlock()
+	if _, err := o.postCtx(ctx, o.baseURL()+"/web/session/destroy", map[string]any{}); err != nil {
+		return fmt.Errorf("logout error: %w", err)
+	}
+	o.UID = 0
+	return nil
+}
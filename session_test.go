@@ -0,0 +1,176 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		var message struct {
+			Params map[string]any `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		var result any
+		response := map[string]any{"jsonrpc": "2.0", "id": 1}
+		switch {
+		case r.URL.Path == "/web/session/authenticate" && message.Params["password"] != "secret":
+			response["error"] = map[string]any{"code": 200, "message": "Odoo Server Error", "data": map[string]any{
+				"name": "odoo.exceptions.AccessDenied", "message": "Access Denied", "debug": "password " + fmt.Sprint(message.Params["password"]),
+			}}
+		case r.URL.Path == "/web/session/authenticate":
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "abc", Path: "/"})
+			result = map[string]any{"uid": 2.0}
+		case strings.HasPrefix(r.URL.Path, "/web/dataset/call_kw/"):
+			if c, err := r.Cookie("session_id"); err != nil || c.Value != "abc" {
+				t.Errorf("expected the session cookie, got %v", c)
+			}
+			if message.Params["model"] != "res.partner" || message.Params["method"] != "search_count" {
+				t.Errorf("unexpected call_kw params %v", message.Params)
+			}
+			b, _ := json.Marshal(message.Params)
+			if strings.Contains(string(b), "secret") {
+				t.Errorf("password sent with call_kw: %s", b)
+			}
+			result = 4.0
+		case r.URL.Path == "/web/session/destroy":
+			result = true
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if response["error"] == nil {
+			response["result"] = result
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer srv.Close()
+
+	bad := NewOdoo().WithAuthMode(AuthSession).WithDatabase("odoo").WithUsername("admin").WithPassword("wrong")
+	bad.URL = srv.URL + "/jsonrpc"
+	if err := bad.Login(); !errors.Is(err, ErrLogin) || strings.Contains(err.Error(), "wrong") {
+		t.Errorf("expected ErrLogin without the password, got %v", err)
+	}
+	paths = nil
+
+	o := NewOdoo().WithAuthMode(AuthSession).WithDatabase("odoo").WithUsername("admin").WithPassword("secret")
+	o.URL = srv.URL + "/jsonrpc"
+	if err := o.Login(); err != nil {
+		t.Fatal(err)
+	}
+	if o.UID != 2 {
+		t.Errorf("expected uid 2, got %d", o.UID)
+	}
+	count, err := o.Derive(nil).Count("res.partner", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4, got %d", count)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		o.Count("res.partner", nil)
+	}()
+	if err := o.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if err := o.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if o.UID != 0 {
+		t.Errorf("expected uid 0 after logout, got %d", o.UID)
+	}
+	expected := "/web/session/authenticate /web/dataset/call_kw/res.partner/search_count"
+	if got := strings.Join(paths, " "); !strings.HasPrefix(got, expected) || !strings.Contains(got, "/web/session/destroy") {
+		t.Errorf("expected %s and a logout, got %s", expected, got)
+	}
+}
+
+func TestRelogin(t *testing.T) {
+	logins, calls, expired := 0, 0, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			Params map[string]any `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		response := map[string]any{"jsonrpc": "2.0", "id": 1}
+		args, _ := message.Params["args"].([]any)
+		switch message.Params["method"] {
+		case "login":
+			logins++
+			response["result"] = float64(logins + 1)
+			if args[2] != "secret" {
+				response["result"] = false
+			}
+		case "execute_kw":
+			calls++
+			if args[1] != float64(logins+1) || calls == 2 || expired {
+				response["error"] = map[string]any{"code": 100, "message": "Odoo Session Expired", "data": map[string]any{"name": "odoo.http.SessionExpiredException"}}
+			} else {
+				response["result"] = 3.0
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer srv.Close()
+
+	o := NewOdoo().WithDatabase("odoo").WithUsername("admin").WithPassword("wrong")
+	o.URL = srv.URL + "/jsonrpc"
+	if err := o.Login(); !errors.Is(err, ErrLogin) {
+		t.Errorf("expected ErrLogin, got %v", err)
+	}
+	if _, err := o.Count("res.partner", nil); !errors.Is(err, ErrLogin) {
+		t.Errorf("expected ErrLogin, got %v", err)
+	}
+
+	o.WithPassword("secret")
+	logins = 0
+	for i, expected := range []int{1, 2, 2} {
+		count, err := o.Count("res.partner", nil)
+		if err != nil {
+			t.Fatalf("\n[%d]: %v", i, err)
+		}
+		if count != 3 || logins != expected || o.UID != expected+1 {
+			t.Errorf("\n[%d]: expected count 3 after %d logins, got %d after %d logins as %d", i, expected, count, logins, o.UID)
+		}
+	}
+
+	o.WithPassword("rotated")
+	expired = true
+	if _, err := o.Count("res.partner", nil); !errors.Is(err, ErrLogin) || !strings.Contains(err.Error(), "Session Expired") {
+		t.Errorf("expected ErrLogin after the session expired, got %v", err)
+	}
+}
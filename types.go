@@ -0,0 +1,128 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+var jsonFalse = []byte("false")
+
+// Many2One value of a many2one field, the server sends [id, "display name"]
+// or false when empty
+type Many2One struct {
+	ID   int
+	Name string
+}
+
+// NewMany2One returns a Many2One referencing id, e.g. for Create and Update
+func NewMany2One(id int) Many2One {
+	return Many2One{ID: id}
+}
+
+// IsZero reports whether the field is empty
+func (m Many2One) IsZero() bool {
+	return m.ID == 0
+}
+
+// MarshalJSON encodes the id, or false when empty, as expected by create
+// and write
+func (m Many2One) MarshalJSON() ([]byte, error) {
+	if m.ID == 0 {
+		return jsonFalse, nil
+	}
+	return json.Marshal(m.ID)
+}
+
+// UnmarshalJSON decodes false, an id or an [id, "display name"] pair
+func (m *Many2One) UnmarshalJSON(b []byte) error {
+	*m = Many2One{}
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, jsonFalse) || bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] != '[' {
+		return json.Unmarshal(b, &m.ID)
+	}
+	var pair []any
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) == 0 {
+		return nil
+	}
+	id, ok := pair[0].(float64)
+	if !ok {
+		return fmt.Errorf("invalid many2one id %v", pair[0])
+	}
+	m.ID = int(id)
+	if len(pair) > 1 {
+		m.Name, _ = pair[1].(string)
+	}
+	return nil
+}
+
+// IDs value of a one2many or many2many field, Encode writes a non nil IDs
+// field as a single set command replacing the linked records
+type IDs []int
+
+// MarshalJSON encodes the list of ids, [] when nil
+func (ids IDs) MarshalJSON() ([]byte, error) {
+	if ids == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]int(ids))
+}
+
+// UnmarshalJSON decodes false or a list of ids
+func (ids *IDs) UnmarshalJSON(b []byte) error {
+	*ids = nil
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, jsonFalse) || bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	var v []int
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*ids = v
+	return nil
+}
+
+// Selection value of a selection field, the server sends false when empty
+type Selection string
+
+// MarshalJSON encodes the key, or false when empty
+func (s Selection) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return jsonFalse, nil
+	}
+	return json.Marshal(string(s))
+}
+
+// UnmarshalJSON decodes false or the selection key
+func (s *Selection) UnmarshalJSON(b []byte) error {
+	*s = ""
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, jsonFalse) || bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	return json.Unmarshal(b, (*string)(s))
+}
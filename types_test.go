@@ -0,0 +1,90 @@
+// odoojrpc - go library to access Odoo server via Json RPC
+// Copyright (C) 2021  Peter Preeper
+
+// This library is free software; you can redistribute it and/or
+// modify it under the terms of the GNU Lesser General Public
+// License as published by the Free Software Foundation; either
+// version 2.1 of the License, or (at your option) any later version.
+
+// This library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// Lesser General Public License for more details.
+
+// You should have received a copy of the GNU Lesser General Public
+// License along with this library; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA  02110-1301
+// USA
+package odoojrpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testOrder struct {
+	ID        int       `odoo:"id"`
+	PartnerID Many2One  `odoo:"partner_id"`
+	UserID    *Many2One `odoo:"user_id"`
+	TagIDs    IDs       `odoo:"tag_ids"`
+	State     Selection `odoo:"state"`
+	Note      string    `odoo:"note,omitempty"`
+}
+
+func TestDecodeTypes(t *testing.T) {
+	patterns := []struct {
+		rec      map[string]any
+		expected testOrder
+	}{
+		{
+			map[string]any{"id": 1.0, "partner_id": []any{3.0, "Azure"}, "user_id": []any{2.0, "Admin"}, "tag_ids": []any{4.0, 5.0}, "state": "draft"},
+			testOrder{ID: 1, PartnerID: Many2One{3, "Azure"}, UserID: &Many2One{2, "Admin"}, TagIDs: IDs{4, 5}, State: "draft"},
+		},
+		{
+			map[string]any{"id": 2.0, "partner_id": false, "user_id": false, "tag_ids": []any{}, "state": false},
+			testOrder{ID: 2, TagIDs: IDs{}},
+		},
+	}
+	for i, pattern := range patterns {
+		var got testOrder
+		if err := Decode(pattern.rec, &got); err != nil {
+			t.Fatalf("\n[%d]: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, pattern.expected) {
+			t.Errorf("\n[%d]: expected %+v, got %+v", i, pattern.expected, got)
+		}
+	}
+}
+
+func TestEncodeTypes(t *testing.T) {
+	patterns := []struct {
+		order    testOrder
+		expected string
+	}{
+		{testOrder{ID: 1, PartnerID: NewMany2One(3), TagIDs: IDs{4, 5}}, `{"partner_id":3,"state":false,"tag_ids":[[6,0,[4,5]]],"user_id":null}`},
+		{testOrder{State: "sale"}, `{"partner_id":false,"state":"sale","user_id":null}`},
+		{testOrder{TagIDs: IDs{}}, `{"partner_id":false,"state":false,"tag_ids":[[6,0,[]]],"user_id":null}`},
+	}
+	for i, pattern := range patterns {
+		record, err := Encode(pattern.order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != pattern.expected {
+			t.Errorf("\n[%d]: expected %s, got %s", i, pattern.expected, b)
+		}
+	}
+
+	b, err := json.Marshal([]any{[]any{"tag_ids", "in", IDs{1, 2}}, []any{"tag_ids", "not in", IDs(nil)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[["tag_ids","in",[1,2]],["tag_ids","not in",[]]]`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+}